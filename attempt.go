@@ -0,0 +1,157 @@
+package gojsonclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// OnAttemptFunc is a function that is called after each attempt to execute a request.
+type OnAttemptFunc func(ctx context.Context, info *AttemptInfo)
+
+// AttemptInfo describes the outcome of a single attempt to execute a request.
+type AttemptInfo struct {
+	// Attempt is the attempt number (1-based).
+	Attempt int
+
+	// Method is the HTTP request method.
+	Method string
+
+	// URI is the request URI, including the client's base URI.
+	URI string
+
+	// StatusCode is the HTTP response status code, or 0 if no response was received.
+	StatusCode int
+
+	// Duration is the time it took to execute the attempt.
+	Duration time.Duration
+
+	// Err is the error returned by the attempt, if any.
+	Err error
+
+	// Class is the classification of Err and StatusCode.
+	Class ErrorClass
+}
+
+// ErrorClass is a classification of an error that occurred during an attempt.
+type ErrorClass int
+
+const (
+	// ErrorClassNone indicates that no error occurred.
+	ErrorClassNone ErrorClass = iota
+
+	// ErrorClassTimeout indicates that a timeout occurred.
+	ErrorClassTimeout
+
+	// ErrorClassConnRefused indicates that the connection was refused.
+	ErrorClassConnRefused
+
+	// ErrorClassDNS indicates that the host name could not be resolved.
+	ErrorClassDNS
+
+	// ErrorClassTLS indicates a failed TLS handshake or certificate verification.
+	ErrorClassTLS
+
+	// ErrorClassHTTPStatus indicates an HTTP response status code outside the 2xx and 3xx ranges.
+	ErrorClassHTTPStatus
+
+	// ErrorClassDecode indicates that the response body could not be decoded.
+	ErrorClassDecode
+
+	// ErrorClassCanceled indicates that the context was canceled.
+	ErrorClassCanceled
+
+	// ErrorClassConnClosed indicates that the connection was closed while reading the response body.
+	ErrorClassConnClosed
+
+	// ErrorClassOther indicates any other error.
+	ErrorClassOther
+)
+
+// WithOnAttempt configures a Client to call fun after each attempt to execute a request.
+// Any number of functions may be added. They are called in the order they were added.
+func WithOnAttempt(fun OnAttemptFunc) ClientOpt {
+	return func(client *Client) {
+		client.onAttempt = append(client.onAttempt, fun)
+	}
+}
+
+// ClassifyError returns the classification of err and statusCode. statusCode may be 0 if no response was received.
+func ClassifyError(err error, statusCode int) ErrorClass { //nolint:cyclop // simple switch
+	var (
+		dnsErr       *net.DNSError
+		netErr       net.Error
+		certErr      *tls.CertificateVerificationError
+		alertErr     tls.AlertError
+		recordErr    tls.RecordHeaderError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		decodeErr    *DecodeError
+		closedErr    *ConnectionClosedError
+	)
+
+	switch {
+	case err == nil && (statusCode == 0 || statusCode < http.StatusBadRequest):
+		return ErrorClassNone
+
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+
+	case errors.As(err, &dnsErr):
+		return ErrorClassDNS
+
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorClassConnRefused
+
+	case errors.As(err, &certErr), errors.As(err, &alertErr), errors.As(err, &recordErr),
+		errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return ErrorClassTLS
+
+	case statusCode >= http.StatusBadRequest:
+		return ErrorClassHTTPStatus
+
+	case errors.As(err, &closedErr):
+		return ErrorClassConnClosed
+
+	case errors.As(err, &decodeErr):
+		return ErrorClassDecode
+
+	default:
+		return ErrorClassOther
+	}
+}
+
+// String implements fmt.Stringer.
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassNone:
+		return "none"
+	case ErrorClassTimeout:
+		return "timeout"
+	case ErrorClassConnRefused:
+		return "conn_refused"
+	case ErrorClassDNS:
+		return "dns"
+	case ErrorClassTLS:
+		return "tls"
+	case ErrorClassHTTPStatus:
+		return "http_status"
+	case ErrorClassDecode:
+		return "decode"
+	case ErrorClassCanceled:
+		return "canceled"
+	case ErrorClassConnClosed:
+		return "conn_closed"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,93 @@
+package gojsonclient
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestClassifyError(t *testing.T) {
+	errTest := errors.New("test") //nolint:goerr113 // dynamic error is okay here
+
+	tests := []struct {
+		name       string
+		err        error
+		statusCode int
+		want       ErrorClass
+	}{
+		{"none", nil, http.StatusOK, ErrorClassNone},
+		{"canceled", fmt.Errorf("wrapped: %w", context.Canceled), 0, ErrorClassCanceled},
+		{"deadline", context.DeadlineExceeded, 0, ErrorClassTimeout},
+		{"net timeout", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, 0, ErrorClassTimeout},
+		{"dns", &net.DNSError{Err: "no such host", Name: "example.invalid"}, 0, ErrorClassDNS},
+		{"conn refused", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, 0, ErrorClassConnRefused},
+		{"tls", x509.UnknownAuthorityError{}, 0, ErrorClassTLS},
+		{"status", nil, http.StatusNotFound, ErrorClassHTTPStatus},
+		{"status decode", &DecodeError{Err: errTest}, http.StatusInternalServerError, ErrorClassHTTPStatus},
+		{"decode", &DecodeError{Err: errTest}, http.StatusOK, ErrorClassDecode},
+		{"conn closed", fmt.Errorf("wrapped: %w", &ConnectionClosedError{Err: io.ErrUnexpectedEOF}), http.StatusOK, ErrorClassConnClosed},
+		{"other", errTest, 0, ErrorClassOther},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(ClassifyError(test.err, test.statusCode), test.want)
+		})
+	}
+}
+
+func TestDo_OnAttempt(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	var infos []AttemptInfo
+
+	client := New(
+		WithClock(newFakeClock()),
+
+		WithOnAttempt(func(_ context.Context, info *AttemptInfo) {
+			infos = append(infos, *info)
+		}),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(len(infos), 2)
+
+	is.Equal(infos[0].Attempt, 1)
+	is.Equal(infos[0].StatusCode, http.StatusInternalServerError)
+	is.Equal(infos[0].Class, ErrorClassHTTPStatus)
+
+	is.Equal(infos[1].Attempt, 2)
+	is.Equal(infos[1].URI, server.URL)
+	is.Equal(infos[1].Method, http.MethodGet)
+	is.Equal(infos[1].Class, ErrorClassNone)
+}
@@ -0,0 +1,95 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DoChunkedBatch splits items into chunks of at most chunkSize items, creates a Request for each chunk using makeReq,
+// and executes the requests one after the other using Do. Each request is retried independently.
+// It returns the responses of all chunks, in order.
+//
+// If continueOnError is false, DoChunkedBatch stops on the first chunk that fails and returns the responses
+// received so far together with the error. If continueOnError is true, all chunks are executed. The responses
+// of failed chunks are nil, and the errors of all failed chunks are returned joined together.
+func DoChunkedBatch[Item any, Res any](ctx context.Context, client *Client, items []Item, chunkSize int,
+	makeReq func(chunk []Item) *Request[[]Item, Res], continueOnError bool,
+) ([]*Response[Res], error) {
+	if chunkSize < 1 {
+		panic("chunkSize must be >=1")
+	}
+
+	responses := make([]*Response[Res], 0, (len(items)+chunkSize-1)/chunkSize)
+
+	var errs []error
+
+	for start := 0; start < len(items); start += chunkSize {
+		end := min(start+chunkSize, len(items))
+
+		res, err := Do(ctx, client, makeReq(items[start:end]))
+		if err != nil {
+			err = fmt.Errorf("chunk %d-%d: %w", start, end-1, err)
+
+			if !continueOnError {
+				return responses, err
+			}
+
+			errs = append(errs, err)
+		}
+
+		responses = append(responses, res)
+	}
+
+	return responses, errors.Join(errs...)
+}
+
+// DoAll executes reqs using Do, running at most concurrency requests at the same time. Each request is retried
+// independently, using the client's retry function, backoff, and rate limiting. It returns the responses and errors
+// of all requests, in the order of reqs. A request that fails does not affect the other requests.
+//
+// If ctx is canceled, requests that have not been started yet are not executed, and their errors are ctx's error.
+func DoAll[Req any, Res any](ctx context.Context, client *Client, reqs []*Request[Req, Res], concurrency int,
+) ([]*Response[Res], []error) {
+	if concurrency < 1 {
+		panic("concurrency must be >=1")
+	}
+
+	responses := make([]*Response[Res], len(reqs))
+	errs := make([]error, len(reqs))
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range min(concurrency, len(reqs)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for idx := range indexes {
+				responses[idx], errs[idx] = Do(ctx, client, reqs[idx])
+			}
+		}()
+	}
+
+	for idx := range reqs {
+		if ctx.Err() != nil {
+			errs[idx] = ctx.Err()
+			continue
+		}
+
+		select {
+		case indexes <- idx:
+		case <-ctx.Done():
+			errs[idx] = ctx.Err()
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return responses, errs
+}
@@ -0,0 +1,167 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/matryer/is"
+)
+
+func TestDoChunkedBatch(t *testing.T) {
+	is := is.New(t)
+
+	var chunks [][]int
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		var chunk []int
+		_ = json.UnmarshalRead(req.Body, &chunk)
+
+		chunks = append(chunks, chunk)
+
+		_ = json.MarshalWrite(writer, len(chunk))
+	}))
+
+	defer server.Close()
+
+	client := New()
+
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	responses, err := DoChunkedBatch(context.Background(), client, items, 4, func(chunk []int) *Request[[]int, int] {
+		return NewRequest[[]int, int](server.URL, http.MethodPost, chunk)
+	}, false)
+	is.NoErr(err)
+
+	is.Equal(chunks, [][]int{{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 10}})
+
+	is.Equal(len(responses), 3)
+	is.Equal(responses[0].Res, 4)
+	is.Equal(responses[1].Res, 4)
+	is.Equal(responses[2].Res, 2)
+}
+
+func TestDoChunkedBatch_ContinueOnError(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		var chunk []int
+		_ = json.UnmarshalRead(req.Body, &chunk)
+
+		if chunk[0] == 1 {
+			http.Error(writer, "Bad Request", http.StatusBadRequest)
+			return
+		}
+
+		_ = json.MarshalWrite(writer, len(chunk))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	makeReq := func(chunk []int) *Request[[]int, int] {
+		return NewRequest[[]int, int](server.URL, http.MethodPost, chunk)
+	}
+
+	responses, err := DoChunkedBatch(context.Background(), client, items, 4, makeReq, false)
+	is.True(err != nil)
+	is.Equal(len(responses), 0)
+
+	responses, err = DoChunkedBatch(context.Background(), client, items, 4, makeReq, true)
+	is.True(err != nil)
+	is.Equal(len(responses), 3)
+	is.Equal(responses[0], nil)
+	is.Equal(responses[2].Res, 2)
+}
+
+func TestDoAll(t *testing.T) {
+	is := is.New(t)
+
+	var inFlight, maxInFlight atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			prev := maxInFlight.Load()
+			if current <= prev || maxInFlight.CompareAndSwap(prev, current) {
+				break
+			}
+		}
+
+		time.Sleep(5 * time.Millisecond)
+
+		var num int
+		_ = json.UnmarshalRead(req.Body, &num)
+
+		if num == 3 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_ = json.MarshalWrite(writer, num*10)
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()), WithResponseErrors(), WithMaxAttempts(1))
+
+	var reqs []*Request[int, int]
+	for num := range 6 {
+		reqs = append(reqs, NewRequest[int, int](server.URL, http.MethodPost, num))
+	}
+
+	responses, errs := DoAll(context.Background(), client, reqs, 2)
+
+	is.Equal(len(responses), 6)
+	is.Equal(len(errs), 6)
+	is.True(maxInFlight.Load() <= 2)
+
+	for num := range 6 {
+		if num == 3 {
+			var resErr *ResponseError
+			is.True(errors.As(errs[num], &resErr))
+			is.Equal(responses[num], nil)
+
+			continue
+		}
+
+		is.NoErr(errs[num])
+		is.Equal(responses[num].Res, num*10)
+	}
+}
+
+func TestDoAll_Canceled(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_ = json.MarshalWrite(writer, 1)
+	}))
+
+	defer server.Close()
+
+	client := New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reqs := []*Request[any, int]{
+		NewRequest[any, int](server.URL, http.MethodGet, nil),
+		NewRequest[any, int](server.URL, http.MethodGet, nil),
+	}
+
+	responses, errs := DoAll(ctx, client, reqs, 1)
+
+	is.Equal(responses, []*Response[int]{nil, nil})
+	is.True(errors.Is(errs[0], context.Canceled))
+	is.True(errors.Is(errs[1], context.Canceled))
+}
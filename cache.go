@@ -0,0 +1,159 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ResponseCache stores responses for conditional requests made using WithConditionalGet.
+// Responses are keyed by request URL. Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the response stored for key, if any.
+	Get(key string) (*CachedResponse, bool)
+
+	// Set stores res for key, replacing any previously stored response.
+	Set(key string, res *CachedResponse)
+}
+
+// CachedResponse is a response stored in a ResponseCache.
+type CachedResponse struct {
+	// ETag is the entity tag of the response.
+	ETag string
+
+	// Header is the HTTP response header.
+	Header http.Header
+
+	// Body is the raw (uncompressed) response body.
+	Body []byte
+}
+
+// MemoryCache is a ResponseCache that stores responses in memory. Entries are never evicted.
+type MemoryCache struct {
+	mutex   sync.Mutex
+	entries map[string]*CachedResponse
+}
+
+var _ ResponseCache = (*MemoryCache)(nil)
+
+// NewMemoryCache returns a new, empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: map[string]*CachedResponse{},
+	}
+}
+
+// WithResponseCache configures a Client to use cache to store responses for conditional requests made using
+// WithConditionalGet. By default, a Client uses its own MemoryCache.
+func WithResponseCache(cache ResponseCache) ClientOpt {
+	if cache == nil {
+		panic("cache must not be nil")
+	}
+
+	return func(client *Client) {
+		client.responseCache = cache
+	}
+}
+
+// WithConditionalGet configures a Request to revalidate a response previously stored in the client's response
+// cache (see WithResponseCache) by sending its ETag in the If-None-Match header. If the server responds with
+// http.StatusNotModified, Response.Res is decoded from the cached response body, and Response.StatusCode is
+// http.StatusNotModified. Successful responses that carry an ETag header are stored in the cache.
+//
+// The request data is not sent, as revalidation requests should not have a body. This can be overridden by
+// applying WithSendBody afterwards. If the Request has also been configured using WithIfNoneMatch, that ETag
+// is sent instead of the cached one.
+func WithConditionalGet[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.conditionalGet = true
+		req.sendBody = sendBodyOmit
+	}
+}
+
+// Get implements ResponseCache.
+func (c *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	res, ok := c.entries[key]
+
+	return res, ok
+}
+
+// Set implements ResponseCache.
+func (c *MemoryCache) Set(key string, res *CachedResponse) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[key] = res
+}
+
+// setIfNoneMatch sets the If-None-Match header of httpReq to the ETag of the cached response, if any.
+func setIfNoneMatch(cache ResponseCache, httpReq *http.Request) {
+	if httpReq.Header.Get("If-None-Match") != "" {
+		return
+	}
+
+	if cached, ok := cache.Get(httpReq.URL.String()); ok {
+		httpReq.Header.Set("If-None-Match", cached.ETag)
+	}
+}
+
+// cachedResponse returns the response stored in the client's response cache for httpReq, decoded using req.
+// If there is no cached response, it returns false.
+func cachedResponse[Req any, Res any](client *Client, httpReq *http.Request, httpRes *http.Response,
+	req *Request[Req, Res],
+) (*Response[Res], bool, error) {
+	cached, ok := client.responseCache.Get(httpReq.URL.String())
+	if !ok {
+		return nil, false, nil
+	}
+
+	cachedRes := *httpRes
+	cachedRes.Body = io.NopCloser(bytes.NewReader(cached.Body))
+
+	jsonRes := decodeTarget(req)
+	if err := unmarshalFunc(client, req)(&cachedRes, jsonRes); err != nil {
+		return nil, true, &DecodeError{
+			Err:        err,
+			StatusCode: httpRes.StatusCode,
+			RawBody:    cached.Body[:min(len(cached.Body), client.decodeErrorBodySize)],
+		}
+	}
+
+	res := newResponse[Res](httpRes, nil)
+	res.Res = *jsonRes
+
+	return res, true, nil
+}
+
+// cacheResponse stores httpRes in cache if it carries an ETag header, and returns a copy of httpRes whose body
+// yields the full response body again. If limitedBody is not nil and the response body exceeds its limit,
+// httpRes is not stored.
+func cacheResponse(cache ResponseCache, httpReq *http.Request, httpRes *http.Response, limitedBody *limitedBody,
+) (*http.Response, error) {
+	etag := httpRes.Header.Get("ETag")
+	if etag == "" {
+		return httpRes, nil
+	}
+
+	body, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	if limitedBody == nil || !limitedBody.exceeded {
+		cache.Set(httpReq.URL.String(), &CachedResponse{
+			ETag:   etag,
+			Header: httpRes.Header.Clone(),
+			Body:   body,
+		})
+	}
+
+	bufferedRes := *httpRes
+	bufferedRes.Body = io.NopCloser(bytes.NewReader(body))
+
+	return &bufferedRes, nil
+}
@@ -0,0 +1,62 @@
+package gojsonclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDo_ConditionalGet(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			is.Equal(req.Header.Get("If-None-Match"), "")
+
+			writer.Header().Set("ETag", `"v1"`)
+			_, _ = writer.Write([]byte(`{"reply":"Hello, client!"}`))
+
+			return
+		}
+
+		is.Equal(req.Header.Get("If-None-Match"), `"v1"`)
+
+		writer.WriteHeader(http.StatusNotModified)
+	}))
+
+	defer server.Close()
+
+	cache := NewMemoryCache()
+
+	client := New(
+		WithResponseCache(cache),
+		WithResponseErrors(),
+	)
+
+	req := NewRequest(server.URL+"/config", http.MethodGet, (*testReq)(nil),
+		WithConditionalGet[*testReq, *testRes](),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.StatusCode, http.StatusOK)
+	is.Equal(res.Res.Reply, "Hello, client!")
+
+	cached, ok := cache.Get(server.URL + "/config")
+	is.True(ok)
+	is.Equal(cached.ETag, `"v1"`)
+
+	res, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.StatusCode, http.StatusNotModified)
+	is.Equal(res.Res.Reply, "Hello, client!")
+
+	is.Equal(attempts, 2)
+}
@@ -0,0 +1,46 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+)
+
+// CircuitBreaker decides whether requests may be made, based on the outcome of previous requests.
+// Implementations must be safe to use concurrently.
+type CircuitBreaker interface {
+	// Allow returns true if a request may be made.
+	Allow() bool
+
+	// RecordSuccess records a successful attempt.
+	RecordSuccess()
+
+	// RecordFailure records a failed attempt.
+	RecordFailure()
+}
+
+// ErrCircuitOpen is returned when the circuit breaker configured using WithCircuitBreaker does not allow
+// a request to be made.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// WithCircuitBreaker configures a Client to consult breaker before each attempt to execute a request.
+// If breaker does not allow the attempt, Do stops and returns a gobackoff.AbortError wrapping ErrCircuitOpen.
+// The outcome of each attempt is recorded in breaker. Attempts that fail because the context was canceled
+// are not recorded.
+func WithCircuitBreaker(breaker CircuitBreaker) ClientOpt {
+	return func(client *Client) {
+		client.circuitBreaker = breaker
+	}
+}
+
+func recordCircuitBreaker(breaker CircuitBreaker, err error) {
+	switch {
+	case breaker == nil, errors.Is(err, context.Canceled):
+		return
+
+	case err == nil:
+		breaker.RecordSuccess()
+
+	default:
+		breaker.RecordFailure()
+	}
+}
@@ -0,0 +1,63 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type testCircuitBreaker struct {
+	maxFailures int
+	successes   int
+	failures    int
+}
+
+var _ CircuitBreaker = (*testCircuitBreaker)(nil)
+
+func TestDo_CircuitBreaker(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+	}))
+
+	defer server.Close()
+
+	breaker := testCircuitBreaker{
+		maxFailures: 2,
+	}
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithCircuitBreaker(&breaker),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+	is.True(errors.Is(err, ErrCircuitOpen))
+
+	is.Equal(attempts, 2)
+	is.Equal(breaker.failures, 2)
+	is.Equal(breaker.successes, 0)
+}
+
+func (b *testCircuitBreaker) Allow() bool {
+	return b.failures < b.maxFailures
+}
+
+func (b *testCircuitBreaker) RecordSuccess() {
+	b.successes++
+}
+
+func (b *testCircuitBreaker) RecordFailure() {
+	b.failures++
+}
@@ -2,47 +2,183 @@ package gojsonclient
 
 import (
 	"bytes"
+	"cmp"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/blizzy78/gobackoff"
 	"github.com/go-json-experiment/json"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 // Client is a client for JSON/REST HTTP services.
 type Client struct {
-	logger             *slog.Logger
-	httpClient         *http.Client
-	baseURI            string
-	requestMiddlewares []RequestMiddlewareFunc
-	requestTimeout     time.Duration
-	maxAttempts        int
-	retryFunc          RetryFunc
-	backoff            *gobackoff.Backoff
+	logger              *slog.Logger
+	httpClient          *http.Client
+	baseURI             string
+	requestMiddlewares  []RequestMiddlewareFunc
+	bodyMiddlewares     []BodyAwareMiddlewareFunc
+	responseMiddlewares []ResponseMiddlewareFunc
+	requestTimeout      time.Duration
+	maxAttempts         int
+	idempotentOnly      bool
+	retryFunc           RetryFuncV2
+	backoff             *gobackoff.Backoff
+	responseErrors      bool
+	decompression       bool
+	retryAfterMaxDelay  time.Duration
+	metrics             MetricsHook
+	contentType         string
+	accept              string
+	marshalOptions      []json.Options
+	unmarshalOptions    []json.Options
+	onAttempt           []OnAttemptFunc
+	onRequestSize       []RequestSizeFunc
+	finalizers          []FinalizerFunc
+	coalescer           *coalescer
+	logBodiesMaxLength  int
+	redactedHeaders     []string
+	debugDump           io.Writer
+	debugDumpMutex      sync.Mutex
+	httpClientOpts      []httpClientOpt
+	transportOpts       []transportOpt
+	http1Hosts          []string
+	ownsTransport       bool
+	maxResponseBodySize int64
+	maxRequestBodySize  int64
+	decodeErrorBodySize int
+	rejectNonJSONErrors bool
+	contentTypeOnlyBody bool
+	bodylessMethods     []string
+	unwrapFinalError    bool
+	circuitBreaker      CircuitBreaker
+	clock               Clock
+	assumeContentType   string
+	responseCache       ResponseCache
+	jitter              *decorrelatedJitter
+	clockBackoff        *clockBackoff
+	jitterSource        rand.Source
+	shutdownMutex       sync.Mutex
+	closed              bool
+	inFlight            sync.WaitGroup
 }
 
 // ClientOpt is a function that configures a Client.
 type ClientOpt func(client *Client)
 
 // RequestMiddlewareFunc is a function that modifies an HTTP request.
+// The request's context (see http.Request.Context) carries the values of the context passed to Do,
+// as well as the values added using WithRequestContextValue.
 type RequestMiddlewareFunc func(req *http.Request) error
 
+// ResponseMiddlewareFunc is a function that inspects or modifies an HTTP response before its body is decoded.
+type ResponseMiddlewareFunc func(res *http.Response) error
+
+// RequestModifierFunc is a function that may replace an HTTP request entirely.
+type RequestModifierFunc func(req *http.Request) (*http.Request, error)
+
 // RetryFunc is a function that decides whether to retry an HTTP request.
 // Depending on the outcome of the previous attempt, httpRes and/or err may be nil.
 // A new attempt is made if the function returns a nil error.
 type RetryFunc func(ctx context.Context, httpRes *http.Response, err error) error
 
+// RetryFuncEx is a function that decides whether to retry an HTTP request. In contrast to RetryFunc,
+// it also receives the HTTP request of the previous attempt, so that the decision may depend on its method or URL.
+// Depending on the outcome of the previous attempt, httpReq, httpRes and/or err may be nil.
+// A new attempt is made if the function returns a nil error.
+type RetryFuncEx func(ctx context.Context, httpReq *http.Request, httpRes *http.Response, err error) error
+
+// RetryFuncV2 is a function that decides whether to retry an HTTP request, based on info about the previous attempt.
+// A new attempt is made if the function returns a nil error.
+type RetryFuncV2 func(ctx context.Context, info RetryInfo) error
+
+// RetryInfo contains information about an attempt, passed to a RetryFuncV2.
+type RetryInfo struct {
+	// Attempt is the attempt number (1-based).
+	Attempt int
+
+	// Start is the time at which the first attempt was started.
+	Start time.Time
+
+	// Elapsed is the time elapsed since the first attempt was started.
+	Elapsed time.Duration
+
+	// Request is the HTTP request of the attempt. It may be nil if the request could not be created.
+	Request *http.Request
+
+	// Response is the HTTP response of the attempt. It may be nil if the request failed.
+	// The response body has already been closed.
+	Response *http.Response
+
+	// Err is the error returned by the attempt, if any.
+	Err error
+}
+
 // Request represents a JSON/REST HTTP request.
 type Request[Req any, Res any] struct {
 	uri                string
+	baseURI            string
 	method             string
 	req                Req
+	bodyFunc           func() (Req, error)
+	marshalOnce        bool
+	bufferHint         int
+	multipartParts     []Part
+	rawBody            io.Reader
+	rawBodyOpen        func() (io.ReadCloser, error)
+	rawBodyContentType string
+	sendBody           sendBodyMode
+	bodyless           bool
+	responseMeta       bool
+	queryParams        url.Values
+	pathSegments       []string
+	contentType        string
+	accept             string
+	acceptEncoding     string
+	idempotencyKey     string
+	decompression      decompressionMode
+	header             http.Header
+	contextValues      []contextValue
+	cookies            []*http.Cookie
+	deadlineHeader     string
+	responseWriter     io.Writer
+	readerWrapper      func(reader io.Reader) io.Reader
+	stripComments      bool
+	rawBodyMaxSize     int
+	responseSizeHint   int64
+	requestModifier    RequestModifierFunc
+	beforeSend         RequestMiddlewareFunc
+	headerCallback     ResponseHeaderFunc
+	informational      InformationalFunc
+	responseSchema     *jsonschema.Schema
 	ignoreResponseBody bool
+	conditionalGet     bool
+	idempotent         bool
+	expectETag         string
+	errorOnNullResult  bool
+	emptyBody          emptyBodyMode
+	rateLimitError     bool
+	decodeOnError      bool
+	marshalOptions     []json.Options
+	unmarshalOptions   []json.Options
+	decodeInto         *Res
 	marshalRequest     MarshalJSONFunc[Req]
 	unmarshalResponse  UnmarshalJSONFunc[Res]
 }
@@ -50,16 +186,35 @@ type Request[Req any, Res any] struct {
 // RequestOpt is a function that configures a Request.
 type RequestOpt[Req any, Res any] func(req *Request[Req, Res])
 
+// contextValue is a value added to the context of HTTP requests using WithRequestContextValue.
+type contextValue struct {
+	key   any
+	value any
+}
+
 // MarshalJSONFunc is a function that encodes a value to JSON and outputs it to writer.
 type MarshalJSONFunc[T any] func(writer io.Writer, val T) error
 
 // UnmarshalJSONFunc is a function that decodes JSON from httpRes.Body and stores it in val.
 type UnmarshalJSONFunc[T any] func(httpRes *http.Response, val *T) error
 
+// UnmarshalJSONAttemptFunc is a function that decodes JSON from httpRes.Body and stores it in val.
+// attempt is the current attempt number (1-based).
+type UnmarshalJSONAttemptFunc[T any] func(httpRes *http.Response, attempt int, val *T) error
+
+// ResponseHeaderFunc is a function that inspects the HTTP response headers and status code before the response body
+// is processed. If decode is false, the response body is ignored.
+type ResponseHeaderFunc func(header http.Header, statusCode int) (decode bool, err error)
+
+// InformationalFunc is a function that is called for each informational (1xx) HTTP response,
+// such as 103 Early Hints, received before the final response.
+type InformationalFunc func(code int, header http.Header)
+
 // Response represents a JSON/REST HTTP response.
 type Response[T any] struct {
 	// Res is the value decoded from the response body.
-	// Res will be the default value of T if StatusCode==http.StatusNoContent, or if the response body is ignored.
+	// Res will be the default value of T if StatusCode==http.StatusNoContent or StatusCode==http.StatusNotModified,
+	// if StatusCode is a redirect that has not been followed, or if the response body is ignored.
 	Res T
 
 	// StatusCode is the HTTP response status code.
@@ -67,31 +222,207 @@ type Response[T any] struct {
 
 	// Status is the HTTP response status.
 	Status string
+
+	// Header contains the HTTP response headers.
+	Header http.Header
+
+	// RequestURL is the URL of the request that produced the response. If redirects were followed,
+	// it is the URL of the last request.
+	RequestURL string
+
+	// RequestMethod is the method of the request that produced the response. If redirects were followed,
+	// it is the method of the last request.
+	RequestMethod string
+
+	// RedirectChain contains the URLs of the requests made because of redirects, in the order they were made.
+	// The last URL is the URL of the request that produced the response. It is empty if no redirects were followed.
+	RedirectChain []*url.URL
+
+	// TLS contains information about the TLS connection on which the response was received.
+	// It is nil for responses received over unencrypted connections.
+	TLS *tls.ConnectionState
+
+	// RawBody contains the raw response body if the Request has been configured using WithCaptureRawBody.
+	// It contains at most the maximum number of bytes configured.
+	RawBody []byte
+
+	// BytesWritten is the number of bytes of the response body written to the writer configured using
+	// WithResponseWriter. It is 0 if the Request has not been configured using WithResponseWriter.
+	BytesWritten int64
+
+	// Duration is the time it took to send the request and receive the response headers in the attempt that
+	// produced the response. It does not include reading the response body.
+	Duration time.Duration
+
+	// Attempts is the number of attempts it took to produce the response.
+	Attempts int
+
+	// Trailer contains the HTTP response trailers, such as status details sent by gRPC-JSON transcoding gateways.
+	// Trailers are only available if the response body has been read completely, which is the case if it has been
+	// decoded. Trailer is nil if the server did not send any trailers.
+	Trailer http.Header
+
+	// Meta contains metadata about the response if the Request has been configured using WithResponseMeta.
+	Meta *ResponseMeta
+
+	bodyBytes int64
+}
+
+// DecodeError is returned when the response body could not be decoded.
+type DecodeError struct {
+	// Err is the error returned by the unmarshal function.
+	Err error
+
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+
+	// RawBody contains the raw response body if the Request has been configured using WithCaptureRawBody.
+	// Otherwise, it contains the beginning of the response body, up to the size configured using
+	// WithDecodeErrorBodySize.
+	RawBody []byte
+}
+
+// defaultDecodeErrorBodySize is the default maximum number of bytes of the response body that are kept for
+// DecodeError.RawBody.
+const defaultDecodeErrorBodySize = 1024
+
+// maxDecodeErrorSnippet is the maximum number of bytes of DecodeError.RawBody that are included in the error message.
+const maxDecodeErrorSnippet = 128
+
+// ResponseError is returned when the HTTP response status code is outside the 2xx range
+// and the Client has been configured using WithResponseErrors.
+type ResponseError struct {
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+
+	// Status is the HTTP response status.
+	Status string
+
+	// Body is the raw response body. If the Client is configured with WithMaxResponseBodySize, Body is
+	// truncated to that size.
+	Body []byte
+}
+
+// NullResultError is returned when the response body has been decoded successfully, but the decoded value is nil,
+// for example because the response body is JSON null, and the Request has been configured using WithErrorOnNullResult.
+type NullResultError struct {
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+
+	// Status is the HTTP response status.
+	Status string
+}
+
+// RetriesExhaustedError is returned by Do when the maximum number of attempts has been reached without success.
+// It wraps the *gobackoff.MaxAttemptsError returned by the backoff. If the Client has been configured using
+// WithUnwrapFinalError, it can still be retrieved using errors.As.
+type RetriesExhaustedError struct {
+	// Attempts is the number of attempts made.
+	Attempts int
+
+	// LastStatusCode is the HTTP response status code of the last attempt, or 0 if it did not receive a response.
+	LastStatusCode int
+
+	// LastStatus is the HTTP response status of the last attempt, or empty if it did not receive a response.
+	LastStatus string
+
+	// LastBody is the raw response body of the last attempt, if available. It is available if the last attempt
+	// failed with a *ResponseError, or with a *DecodeError and the Request has been configured using
+	// WithCaptureRawBody.
+	LastBody []byte
+
+	// Err is the *gobackoff.MaxAttemptsError returned by the backoff.
+	Err error
+}
+
+// finalError is returned by Do if the Client has been configured using WithUnwrapFinalError. It has the message of
+// the error of the last attempt, but also wraps the error that would have been returned otherwise.
+type finalError struct {
+	cause   error
+	wrapper error
 }
 
 type httpError string
 
-var _ error = httpError("")
+// marshalOnceKey is the context key for the *marshalOnceBody of a call to Do.
+type marshalOnceKey struct{}
+
+// marshalOnceBody holds the request body encoded by the first attempt of a call to Do.
+type marshalOnceBody struct {
+	done bool
+	body []byte
+}
+
+type emptyBodyMode int
+
+type sendBodyMode int
+
+type decompressionMode int
+
+const (
+	emptyBodyDecode emptyBodyMode = iota
+	emptyBodyAllow
+	emptyBodyRetry
+)
+
+const (
+	sendBodyDefault sendBodyMode = iota
+	sendBodyAlways
+	sendBodyOmit
+)
+
+const (
+	decompressionDefault decompressionMode = iota
+	decompressionEnabled
+	decompressionDisabled
+)
+
+// ErrEmptyBody is returned when the response body is empty but content is expected,
+// and the Request has been configured using WithRetryOnEmptyBody.
+var ErrEmptyBody = errors.New("empty response body")
+
+var (
+	_ error = httpError("")
+	_ error = (*finalError)(nil)
+	_ error = (*DecodeError)(nil)
+	_ error = (*ResponseError)(nil)
+)
 
 // New creates a new Client with the given options.
 //
 // The default options are: slog.Default() as the logger, http.DefaultClient as the HTTP client,
 // request timeout of 30s, maximum number of attempts of 5, gobackoff.New() as the backoff,
-// and a retry function that returns an error if the HTTP response status code is http.StatusBadRequest.
+// "application/json; charset=UTF-8" as the Content-Type header, "application/json" as the Accept header,
+// "application/json" as the assumed Content-Type of responses without one, automatic decompression of gzip-encoded responses,
+// Authorization, Cookie, and Set-Cookie as the redacted headers, 1024 bytes of the response body kept for decode errors,
+// and a retry function that returns an error if IsRetryable
+// returns false.
 func New(opts ...ClientOpt) *Client {
 	client := Client{
-		logger:         slog.Default(),
-		httpClient:     http.DefaultClient,
-		requestTimeout: 30 * time.Second,
-		maxAttempts:    5,
-		backoff:        gobackoff.New(),
-
-		retryFunc: func(_ context.Context, httpRes *http.Response, _ error) error {
-			if httpRes != nil && httpRes.StatusCode == http.StatusBadRequest {
-				return httpError(httpRes.Status)
+		logger:              slog.Default(),
+		httpClient:          http.DefaultClient,
+		requestTimeout:      30 * time.Second,
+		maxAttempts:         5,
+		backoff:             gobackoff.New(),
+		decompression:       true,
+		assumeContentType:   "application/json",
+		responseCache:       NewMemoryCache(),
+		clock:               systemClock{},
+		contentType:         "application/json; charset=UTF-8",
+		accept:              "application/json",
+		redactedHeaders:     []string{"Authorization", "Cookie", "Set-Cookie"},
+		decodeErrorBodySize: defaultDecodeErrorBodySize,
+
+		retryFunc: func(_ context.Context, info RetryInfo) error {
+			if IsRetryable(info.Err, info.Response) {
+				return nil
+			}
+
+			if info.Response != nil {
+				return httpError(info.Response.Status)
 			}
 
-			return nil
+			return info.Err
 		},
 	}
 
@@ -99,6 +430,9 @@ func New(opts ...ClientOpt) *Client {
 		opt(&client)
 	}
 
+	client.applyHTTPClientOpts()
+	client.applyJitterOpts()
+
 	return &client
 }
 
@@ -117,6 +451,10 @@ func WithHTTPClient(httpClient *http.Client) ClientOpt {
 }
 
 // WithBaseURI configures a Client to use baseURI as the URI prefix for all requests.
+//
+// Request URIs are resolved relative to baseURI as if baseURI ended with a slash, so that "/users" and "users"
+// both resolve to "https://example.com/api/users" for a baseURI of "https://example.com/api" or
+// "https://example.com/api/". Absolute request URIs are used as is. Queries of request URIs are preserved.
 func WithBaseURI(baseURI string) ClientOpt {
 	return func(client *Client) {
 		client.baseURI = baseURI
@@ -131,6 +469,15 @@ func WithRequestMiddleware(fun RequestMiddlewareFunc) ClientOpt {
 	}
 }
 
+// WithResponseMiddleware configures a Client to use fun as a response middleware.
+// Any number of response middlewares may be added. They are run in the order they were added.
+// If a response middleware returns an error, the attempt fails and the retry function decides whether to retry.
+func WithResponseMiddleware(fun ResponseMiddlewareFunc) ClientOpt {
+	return func(client *Client) {
+		client.responseMiddlewares = append(client.responseMiddlewares, fun)
+	}
+}
+
 // WithRequestTimeout configures a Client to use timeout for each HTTP request made.
 func WithRequestTimeout(timeout time.Duration) ClientOpt {
 	return func(client *Client) {
@@ -149,12 +496,67 @@ func WithMaxAttempts(max int) ClientOpt {
 	}
 }
 
+// WithRetryIdempotentOnly configures a Client to retry only requests using idempotent methods, that is,
+// http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, and http.MethodOptions. Requests using
+// other methods, such as http.MethodPost or http.MethodPatch, are attempted only once, so that they cannot cause
+// duplicate side effects. This can be overridden for individual requests using WithIdempotent.
+func WithRetryIdempotentOnly() ClientOpt {
+	return func(client *Client) {
+		client.idempotentOnly = true
+	}
+}
+
 // WithRetry configures a Client to use retry as the retry function.
 func WithRetry(retry RetryFunc) ClientOpt {
 	if retry == nil {
 		panic("retry must not be nil")
 	}
 
+	return func(client *Client) {
+		client.retryFunc = func(ctx context.Context, info RetryInfo) error {
+			return retry(ctx, info.Response, info.Err)
+		}
+	}
+}
+
+// IsRetryable returns whether a request that failed with err and/or received httpRes would be retried by
+// the default retry function. Either of err and httpRes may be nil. It can be used to compose custom retry
+// functions on top of the default behavior.
+//
+// A request is not retried if err is or wraps context.Canceled, or if the HTTP response status code is
+// http.StatusBadRequest, since sending the same request again would yield the same result. All other errors
+// and status codes, including connection errors, timeouts and http.StatusInternalServerError, are considered
+// retryable.
+func IsRetryable(err error, httpRes *http.Response) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	return httpRes == nil || httpRes.StatusCode != http.StatusBadRequest
+}
+
+// WithRetryEx configures a Client to use retry as the retry function.
+// In contrast to WithRetry, retry also receives the HTTP request of the previous attempt.
+func WithRetryEx(retry RetryFuncEx) ClientOpt {
+	if retry == nil {
+		panic("retry must not be nil")
+	}
+
+	return func(client *Client) {
+		client.retryFunc = func(ctx context.Context, info RetryInfo) error {
+			return retry(ctx, info.Request, info.Response, info.Err)
+		}
+	}
+}
+
+// WithRetryV2 configures a Client to use retry as the retry function.
+// In contrast to WithRetry and WithRetryEx, retry receives a RetryInfo that also contains the attempt number
+// and the time elapsed since the first attempt was started.
+func WithRetryV2(retry RetryFuncV2) ClientOpt {
+	if retry == nil {
+		panic("retry must not be nil")
+	}
+
 	return func(client *Client) {
 		client.retryFunc = retry
 	}
@@ -167,6 +569,101 @@ func WithBackoff(backoff *gobackoff.Backoff) ClientOpt {
 	}
 }
 
+// WithResponseErrors configures a Client to return a *ResponseError if the HTTP response status code
+// is outside the 2xx range, instead of attempting to decode the response body.
+func WithResponseErrors() ClientOpt {
+	return func(client *Client) {
+		client.responseErrors = true
+	}
+}
+
+// WithDefaultContentType configures a Client to use contentType as the value of the Content-Type header for
+// all requests, unless a Request has been configured using WithContentType.
+func WithDefaultContentType(contentType string) ClientOpt {
+	return func(client *Client) {
+		client.contentType = contentType
+	}
+}
+
+// WithDefaultAccept configures a Client to use accept as the value of the Accept header for all requests,
+// unless a Request has been configured using WithAccept.
+func WithDefaultAccept(accept string) ClientOpt {
+	return func(client *Client) {
+		client.accept = accept
+	}
+}
+
+// WithDefaultMarshalOptions configures a Client to use opts when encoding request data for all requests,
+// unless a Request has been configured using WithMarshalRequestFunc. WithDefaultMarshalOptions may be used
+// multiple times to add more options.
+func WithDefaultMarshalOptions(opts ...json.Options) ClientOpt {
+	return func(client *Client) {
+		client.marshalOptions = append(client.marshalOptions, opts...)
+	}
+}
+
+// WithDefaultUnmarshalOptions configures a Client to use opts when decoding response bodies for all requests,
+// unless a Request has been configured using WithUnmarshalResponseFunc. The options are also used by DoStream
+// and DoSSE. WithDefaultUnmarshalOptions may be used multiple times to add more options.
+func WithDefaultUnmarshalOptions(opts ...json.Options) ClientOpt {
+	return func(client *Client) {
+		client.unmarshalOptions = append(client.unmarshalOptions, opts...)
+	}
+}
+
+// WithBodylessMethods configures a Client to never send the request data as the request body for requests using
+// any of methods, such as http.MethodGet or http.MethodDelete. This can be overridden for individual requests
+// using WithSendBody.
+func WithBodylessMethods(methods ...string) ClientOpt {
+	return func(client *Client) {
+		client.bodylessMethods = append(client.bodylessMethods, methods...)
+	}
+}
+
+// WithUnwrapFinalError configures a Client such that the error returned by Do has the message of the error of the
+// last attempt, instead of that of the *RetriesExhaustedError, *gobackoff.MaxAttemptsError, or *gobackoff.AbortError
+// wrapping it. The returned error still wraps both the error of the last attempt and the wrapper, so errors.As
+// and errors.Is work for either.
+func WithUnwrapFinalError() ClientOpt {
+	return func(client *Client) {
+		client.unwrapFinalError = true
+	}
+}
+
+// WithAssumeContentType configures a Client to set the Content-Type header of responses that have a body but no
+// Content-Type header to contentType, before the response middlewares and the unmarshal function are called.
+// If contentType is empty, the Content-Type header of responses is left untouched.
+func WithAssumeContentType(contentType string) ClientOpt {
+	return func(client *Client) {
+		client.assumeContentType = contentType
+	}
+}
+
+// WithDecompression configures a Client to transparently decompress response bodies with a Content-Encoding
+// of gzip before they are decoded. This is only necessary if the Accept-Encoding header is set manually, for
+// example by a request middleware, since the HTTP transport will otherwise decompress the response body itself.
+// Callers that handle compressed responses themselves may want to disable this.
+// The setting can be overridden for individual requests using WithRequestDecompression.
+func WithDecompression(enabled bool) ClientOpt {
+	return func(client *Client) {
+		client.decompression = enabled
+	}
+}
+
+// WithRespectRetryAfter configures a Client to honor the Retry-After header (in seconds or as an HTTP date) of
+// http.StatusTooManyRequests and http.StatusServiceUnavailable responses. If the retry function decides to retry,
+// the next attempt is delayed by the time requested by the server, but by no more than maxDelay.
+// Since the backoff's own delay cannot be replaced, it still applies in addition to that delay.
+func WithRespectRetryAfter(maxDelay time.Duration) ClientOpt {
+	if maxDelay <= 0 {
+		panic("maxDelay must be >0")
+	}
+
+	return func(client *Client) {
+		client.retryAfterMaxDelay = maxDelay
+	}
+}
+
 // Use configures c to use fun as a request middleware. Any number of request middlewares may be added.
 //
 // A Client should usually be configured using WithRequestMiddleware, but it may sometimes be necessary to add new
@@ -176,19 +673,14 @@ func (c *Client) Use(fun RequestMiddlewareFunc) {
 }
 
 // NewRequest creates a new Request with the given client, URI, method, request data, and options.
+// Unless configured otherwise, the request data is encoded and the response body is decoded using
+// json.MarshalWrite and json.UnmarshalRead, with the client's default options (see WithDefaultMarshalOptions
+// and WithDefaultUnmarshalOptions).
 func NewRequest[Req any, Res any](uri string, method string, req Req, opts ...RequestOpt[Req, Res]) *Request[Req, Res] {
 	request := Request[Req, Res]{
 		uri:    uri,
 		method: method,
 		req:    req,
-
-		marshalRequest: func(writer io.Writer, val Req) error {
-			return json.MarshalWrite(writer, val)
-		},
-
-		unmarshalResponse: func(httpRes *http.Response, val *Res) error {
-			return json.UnmarshalRead(httpRes.Body, val)
-		},
 	}
 
 	for _, opt := range opts {
@@ -198,6 +690,56 @@ func NewRequest[Req any, Res any](uri string, method string, req Req, opts ...Re
 	return &request
 }
 
+// NewGet creates a new Request using http.MethodGet, without request data. No request body is sent
+// unless the Request is configured using WithSendBody.
+func NewGet[Req any, Res any](uri string, opts ...RequestOpt[Req, Res]) *Request[Req, Res] {
+	var req Req
+	return NewRequest(uri, http.MethodGet, req, withoutBody(opts)...)
+}
+
+// NewPost creates a new Request using http.MethodPost.
+func NewPost[Req any, Res any](uri string, req Req, opts ...RequestOpt[Req, Res]) *Request[Req, Res] {
+	return NewRequest(uri, http.MethodPost, req, opts...)
+}
+
+// NewPut creates a new Request using http.MethodPut.
+func NewPut[Req any, Res any](uri string, req Req, opts ...RequestOpt[Req, Res]) *Request[Req, Res] {
+	return NewRequest(uri, http.MethodPut, req, opts...)
+}
+
+// NewPatch creates a new Request using http.MethodPatch.
+func NewPatch[Req any, Res any](uri string, req Req, opts ...RequestOpt[Req, Res]) *Request[Req, Res] {
+	return NewRequest(uri, http.MethodPatch, req, opts...)
+}
+
+// NewDelete creates a new Request using http.MethodDelete, without request data. No request body is sent
+// unless the Request is configured using WithSendBody.
+func NewDelete[Req any, Res any](uri string, opts ...RequestOpt[Req, Res]) *Request[Req, Res] {
+	var req Req
+	return NewRequest(uri, http.MethodDelete, req, withoutBody(opts)...)
+}
+
+// withoutBody returns opts, preceded by an option that omits the request body and the Content-Type header,
+// so that opts can override it.
+func withoutBody[Req any, Res any](opts []RequestOpt[Req, Res]) []RequestOpt[Req, Res] {
+	return append([]RequestOpt[Req, Res]{
+		func(req *Request[Req, Res]) {
+			req.sendBody = sendBodyOmit
+			req.bodyless = true
+		},
+	}, opts...)
+}
+
+// RequestPreset returns a RequestOpt that applies all of opts in order. This can be used to define a set of
+// options once and reuse it for many requests. Options passed to NewRequest after the preset take precedence.
+func RequestPreset[Req any, Res any](opts ...RequestOpt[Req, Res]) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		for _, opt := range opts {
+			opt(req)
+		}
+	}
+}
+
 // WithMarshalRequestFunc configures a Request to use fun as the marshal function.
 func WithMarshalRequestFunc[Req any, Res any](fun MarshalJSONFunc[Req]) RequestOpt[Req, Res] {
 	return func(req *Request[Req, Res]) {
@@ -212,112 +754,1246 @@ func WithUnmarshalResponseFunc[Req any, Res any](fun UnmarshalJSONFunc[Res]) Req
 	}
 }
 
-// WithIgnoreResponseBody configures a Request to ignore the response body, regardless of status code.
-// The response body will always be ignored if the status code is http.StatusNoContent.
-func WithIgnoreResponseBody[Req any, Res any]() RequestOpt[Req, Res] {
+// WithRequestBaseURI configures a Request to use baseURI instead of the client's base URI (see WithBaseURI).
+// The request URI is resolved relative to baseURI in the same way. If the request URI is absolute, baseURI is ignored.
+func WithRequestBaseURI[Req any, Res any](baseURI string) RequestOpt[Req, Res] {
 	return func(req *Request[Req, Res]) {
-		req.ignoreResponseBody = true
+		req.baseURI = baseURI
 	}
 }
 
-// Do executes req with client and returns the response.
-//
-// If the request data is nil, the request will be made without a body.
-// If the response status code is http.StatusNoContent or the response body should be ignored,
-// Response.Res will be the default value of Res.
-//
-// If an HTTP request fails, it is retried using backoff according to the retry function, up to the
-// maximum number of attempts.
-// If the context is canceled, or if the retry function returns a non-nil error, Do stops and returns
-// a gobackoff.AbortError.
-//
-// Do is safe to call concurrently with the same Request.
-func Do[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res]) (*Response[Res], error) {
-	var res *Response[Res]
-
-	err := client.backoff.Do(ctx, func(ctx context.Context) error {
-		var (
-			httpRes *http.Response
-			err     error
-		)
+// WithEscapedPath configures a Request to append segments to the path of the request URI, separated by slashes.
+// Each segment is escaped using url.PathEscape, so that it may safely contain arbitrary characters,
+// including slashes. Any query or fragment of the request URI is preserved.
+func WithEscapedPath[Req any, Res any](segments ...string) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.pathSegments = append(req.pathSegments, segments...)
+	}
+}
 
-		res, httpRes, err = do(ctx, client, req) //nolint:bodyclose // body is already closed
-		if errors.Is(err, context.Canceled) {
-			return &gobackoff.AbortError{
-				Err: err,
-			}
+// WithQueryParams configures a Request to add values to the query of the request URI.
+// Any query already present in the URI is preserved. Repeated keys result in repeated query parameters.
+// WithQueryParams may be used multiple times to add more values.
+func WithQueryParams[Req any, Res any](values url.Values) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		if req.queryParams == nil {
+			req.queryParams = url.Values{}
 		}
 
-		if retryErr := client.retryFunc(ctx, httpRes, err); retryErr != nil {
-			return &gobackoff.AbortError{
-				Err: retryErr,
-			}
+		for key, vals := range values {
+			req.queryParams[key] = append(req.queryParams[key], vals...)
 		}
-
-		return err
-	}, client.maxAttempts)
-
-	if err != nil {
-		return nil, err //nolint:wrapcheck // we don't add new info here
 	}
+}
 
-	return res, nil
+// WithContentType configures a Request to use contentType as the value of the Content-Type header instead of
+// the client's default.
+func WithContentType[Req any, Res any](contentType string) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.contentType = contentType
+	}
 }
 
-func do[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res]) (*Response[Res], *http.Response, error) {
-	httpReq, err := newHTTPRequest(ctx, client, req)
-	if err != nil {
-		return nil, nil, fmt.Errorf("new HTTP request: %w", err)
+// WithAccept configures a Request to use accept as the value of the Accept header instead of the client's default.
+func WithAccept[Req any, Res any](accept string) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.accept = accept
 	}
+}
 
-	attempt := gobackoff.AttemptFromContext(ctx)
+// WithAcceptEncoding configures a Request to use encoding as the value of the Accept-Encoding header, and to
+// transparently decompress gzip-encoded responses regardless of the client's WithDecompression setting.
+// Since the HTTP transport does not decompress response bodies if the Accept-Encoding header is set manually,
+// this allows compression to be requested for individual endpoints, for example when the transport's own
+// compression has been disabled using http.Transport.DisableCompression.
+// The decompression behavior can be overridden by applying WithRequestDecompression afterwards.
+func WithAcceptEncoding[Req any, Res any](encoding string) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.acceptEncoding = encoding
+		req.decompression = decompressionEnabled
+	}
+}
 
-	client.logger.InfoContext(ctx, "execute HTTP request",
-		slog.Group("request",
-			slog.String("uri", httpReq.URL.String()),
-			slog.String("method", httpReq.Method),
-		),
-		slog.Int("attempt", attempt),
-	)
+// WithRequestDecompression configures a Request to transparently decompress gzip-encoded response bodies
+// if enabled is true, overriding the client's WithDecompression setting.
+func WithRequestDecompression[Req any, Res any](enabled bool) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.decompression = decompressionDisabled
+		if enabled {
+			req.decompression = decompressionEnabled
+		}
+	}
+}
 
-	ctx, cancel := context.WithTimeout(ctx, client.requestTimeout) //nolint:ineffassign,staticcheck // better be safe than sorry
-	defer cancel()
+// WithResponseWriter configures a Request to copy the response body verbatim to writer instead of decoding it.
+// Response.Res will be the default value of Res, and Response.BytesWritten will be the number of bytes written.
+// This is useful for endpoints that return non-JSON content or large downloads, and is usually combined
+// with WithAccept.
+// Nothing is written if the response body is ignored, for example for http.StatusNoContent.
+//
+// If the request is retried, writer may already have received parts of the response body of earlier attempts.
+func WithResponseWriter[Req any, Res any](writer io.Writer) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.responseWriter = writer
+	}
+}
 
-	httpRes, err := client.httpClient.Do(httpReq)
-	if err != nil {
-		return nil, httpRes, fmt.Errorf("execute HTTP request: %w", err)
+// WithResponseReaderWrapper configures a Request to wrap the response body using fun before it is processed
+// further, for example to decrypt or transform it. If the Request has also been configured using
+// WithCaptureRawBody, the raw response body is captured before it is wrapped. If the reader returned by fun
+// implements io.Closer, it is closed after the response body has been processed.
+func WithResponseReaderWrapper[Req any, Res any](fun func(reader io.Reader) io.Reader) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.readerWrapper = fun
 	}
+}
 
-	defer httpRes.Body.Close() //nolint:errcheck // we're only reading
+// WithContentTypeOnlyWithBody configures a Client to only send the Content-Type header if a request has a body,
+// since some servers reject body-less requests, such as GET requests, that carry one. The Accept header is
+// always sent.
+func WithContentTypeOnlyWithBody() ClientOpt {
+	return func(client *Client) {
+		client.contentTypeOnlyBody = true
+	}
+}
 
-	res, err := response(httpRes, req)
-	if err != nil {
-		return nil, httpRes, fmt.Errorf("get response: %w", err)
+// WithDecodeErrorBodySize configures a Client to keep at most maxSize bytes of the beginning of the response body
+// while decoding it, so that they can be included in DecodeError.RawBody if decoding fails. This helps to diagnose
+// unexpected responses such as HTML error pages. If maxSize is 0, the response body is not kept. The default
+// is 1024 bytes. Requests configured using WithCaptureRawBody use the raw body captured instead.
+func WithDecodeErrorBodySize(maxSize int) ClientOpt {
+	if maxSize < 0 {
+		panic("maxSize must be >=0")
 	}
 
-	return res, httpRes, nil
+	return func(client *Client) {
+		client.decodeErrorBodySize = maxSize
+	}
 }
 
-func newHTTPRequest[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res]) (*http.Request, error) {
-	var jsonReqData io.Reader = http.NoBody
-
-	if any(req.req) != nil {
-		buf := bytes.Buffer{}
+// WithCaptureRawBody configures a Request to capture the raw response body and store it in Response.RawBody,
+// as well as in DecodeError.RawBody if the response body could not be decoded. At most maxSize bytes are captured.
+func WithCaptureRawBody[Req any, Res any](maxSize int) RequestOpt[Req, Res] {
+	if maxSize < 1 {
+		panic("maxSize must be >=1")
+	}
 
-		if err := req.marshalRequest(&buf, req.req); err != nil {
-			return nil, fmt.Errorf("encode request body: %w", err)
-		}
+	return func(req *Request[Req, Res]) {
+		req.rawBodyMaxSize = maxSize
+	}
+}
 
-		jsonReqData = &buf
+// WithResponseSizeHint configures a Request to expect a response body of sizeBytes bytes, for example as
+// reported by a previous HEAD request. The buffer used to capture the raw response body (see WithCaptureRawBody)
+// is preallocated accordingly, reducing reallocations for large responses. Response bodies of a different size
+// are still captured correctly.
+func WithResponseSizeHint[Req any, Res any](sizeBytes int64) RequestOpt[Req, Res] {
+	if sizeBytes < 0 {
+		panic("sizeBytes must be >=0")
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, req.method, client.baseURI+req.uri, jsonReqData)
-	if err != nil {
+	return func(req *Request[Req, Res]) {
+		req.responseSizeHint = sizeBytes
+	}
+}
+
+// WithAllowEmptyBody configures a Request to accept an empty response body regardless of status code.
+// Response.Res will be the default value of Res if the response body is empty.
+//
+// WithAllowEmptyBody and WithRetryOnEmptyBody are mutually exclusive, the option applied last wins.
+func WithAllowEmptyBody[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.emptyBody = emptyBodyAllow
+	}
+}
+
+// WithRetryOnEmptyBody configures a Request to treat an empty response body as a transient failure.
+// If the response body is empty but content is expected, a new attempt is made regardless of the retry
+// function, up to the maximum number of attempts. The error returned by the last attempt is ErrEmptyBody.
+//
+// WithAllowEmptyBody and WithRetryOnEmptyBody are mutually exclusive, the option applied last wins.
+func WithRetryOnEmptyBody[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.emptyBody = emptyBodyRetry
+	}
+}
+
+// WithHeader configures a Request to add value to the request header key. Request headers are applied after the
+// default Content-Type and Accept headers, but before the client's request middlewares.
+// WithHeader and WithHeaders may be used multiple times to add more values.
+func WithHeader[Req any, Res any](key string, value string) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		if req.header == nil {
+			req.header = http.Header{}
+		}
+
+		req.header.Add(key, value)
+	}
+}
+
+// WithOptionalHeader configures a Request to add value to the request header key, but only if value is not empty.
+// Otherwise, the option has no effect. It is applied in the same way as WithHeader.
+func WithOptionalHeader[Req any, Res any](key string, value string) RequestOpt[Req, Res] {
+	if value == "" {
+		return func(_ *Request[Req, Res]) {}
+	}
+
+	return WithHeader[Req, Res](key, value)
+}
+
+// WithHeaders configures a Request to add all values in header to the request headers. Request headers are
+// applied after the default Content-Type and Accept headers, but before the client's request middlewares.
+// WithHeader and WithHeaders may be used multiple times to add more values.
+func WithHeaders[Req any, Res any](header http.Header) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		if req.header == nil {
+			req.header = http.Header{}
+		}
+
+		for key, vals := range header {
+			for _, val := range vals {
+				req.header.Add(key, val)
+			}
+		}
+	}
+}
+
+// WithRequestContextValue configures a Request to add value to the context of its HTTP requests, associated with key,
+// as described for context.WithValue. This can be used to pass per-request metadata, such as a tenant ID,
+// to request middlewares, which can read it using http.Request.Context. WithRequestContextValue may be used
+// multiple times to add more values.
+func WithRequestContextValue[Req any, Res any](key any, value any) RequestOpt[Req, Res] {
+	if key == nil {
+		panic("key must not be nil")
+	}
+
+	if !reflect.TypeOf(key).Comparable() {
+		panic("key must be comparable")
+	}
+
+	return func(req *Request[Req, Res]) {
+		req.contextValues = append(req.contextValues, contextValue{
+			key:   key,
+			value: value,
+		})
+	}
+}
+
+// WithCookie configures a Request to send cookie. WithCookie may be used multiple times to send more cookies.
+func WithCookie[Req any, Res any](cookie *http.Cookie) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.cookies = append(req.cookies, cookie)
+	}
+}
+
+// WithBodyFunc configures a Request to call fun to compute the request data when the request is sent, instead of
+// using the request data passed to NewRequest. This can be used for request bodies that contain a timestamp or nonce.
+//
+// fun is called for every attempt, so every attempt may send a different body. If the Request has also been
+// configured using WithMarshalOnce, fun is called only once per call to Do. If fun returns an error,
+// the attempt fails with that error, and is retried according to the retry function.
+func WithBodyFunc[Req any, Res any](fun func() (Req, error)) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.bodyFunc = fun
+	}
+}
+
+// WithMarshalOnce configures a Request to encode the request body only once per call to Do,
+// and to send the same body for all attempts.
+func WithMarshalOnce[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.marshalOnce = true
+	}
+}
+
+// WithRequestBufferHint configures a Request to preallocate sizeBytes bytes for the encoded request body.
+// This reduces allocations when encoding request bodies of a known large size. Request bodies that turn out
+// to be larger than sizeBytes are still encoded completely.
+func WithRequestBufferHint[Req any, Res any](sizeBytes int) RequestOpt[Req, Res] {
+	if sizeBytes < 0 {
+		panic("sizeBytes must be >=0")
+	}
+
+	return func(req *Request[Req, Res]) {
+		req.bufferHint = sizeBytes
+	}
+}
+
+// WithIdempotent configures a Request to be retried even if its method is not idempotent and the Client has been
+// configured using WithRetryIdempotentOnly. This should only be used if repeating the request is safe, for example
+// because it carries an idempotency key (see WithIdempotencyKey).
+func WithIdempotent[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.idempotent = true
+	}
+}
+
+// WithSendBody configures a Request to send or omit the request data as the request body.
+// By default, the request data is sent unless it is nil or the request method has been configured using
+// WithBodylessMethods.
+func WithSendBody[Req any, Res any](send bool) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.sendBody = sendBodyOmit
+		if send {
+			req.sendBody = sendBodyAlways
+			req.bodyless = false
+		}
+	}
+}
+
+// WithIfNoneMatch configures a Request to revalidate a previously received response by sending etag in the
+// If-None-Match header. The request data is not sent, as revalidation requests should not have a body.
+// This can be overridden by applying WithSendBody afterwards.
+//
+// If the server responds with http.StatusNotModified, Response.Res will be the default value of Res.
+func WithIfNoneMatch[Req any, Res any](etag string) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		if req.header == nil {
+			req.header = http.Header{}
+		}
+
+		req.header.Set("If-None-Match", etag)
+		req.sendBody = sendBodyOmit
+	}
+}
+
+// WithPropagateDeadline configures a Request to send the time remaining until the context deadline to the
+// server in header, in milliseconds. If header is grpc-timeout, the value is sent in gRPC format instead
+// (for example, 1500m). The remaining time is computed anew for each attempt. If the context has no
+// deadline, the header is not sent.
+func WithPropagateDeadline[Req any, Res any](header string) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.deadlineHeader = header
+	}
+}
+
+// WithRequestModifier configures a Request to call fun after the HTTP request has been built and the client's
+// request middlewares have been applied. fun may return a different HTTP request to be sent instead, for example
+// to re-point the request to a different host. fun is called anew for each attempt. If the returned request has
+// a body that cannot be replayed, the body is buffered in memory so that it can be sent again on redirects.
+func WithRequestModifier[Req any, Res any](fun RequestModifierFunc) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.requestModifier = fun
+	}
+}
+
+// WithBeforeSend configures a Request to call fun immediately before each attempt to send the request,
+// after the client's request middlewares have been applied. fun may inspect or modify the fully-built request,
+// for example to sign it. If fun returns an error, the request is not sent and the attempt fails with that error.
+func WithBeforeSend[Req any, Res any](fun RequestMiddlewareFunc) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.beforeSend = fun
+	}
+}
+
+// WithResponseHeaderCallback configures a Request to call fun with the HTTP response headers and status code
+// before the response body is processed. If fun returns false, the response body is ignored as if the Request
+// had been configured using WithIgnoreResponseBody. If fun returns an error, the attempt fails with that error.
+func WithResponseHeaderCallback[Req any, Res any](fun ResponseHeaderFunc) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.headerCallback = fun
+	}
+}
+
+// WithInformationalCallback configures a Request to call fun for each informational (1xx) HTTP response
+// received before the final response, such as 103 Early Hints.
+func WithInformationalCallback[Req any, Res any](fun InformationalFunc) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.informational = fun
+	}
+}
+
+// WithUnmarshalResponseFuncAttempt configures a Request to use fun as the unmarshal function.
+// In contrast to WithUnmarshalResponseFunc, fun also receives the current attempt number.
+func WithUnmarshalResponseFuncAttempt[Req any, Res any](fun UnmarshalJSONAttemptFunc[Res]) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.unmarshalResponse = func(httpRes *http.Response, val *Res) error {
+			attempt := 0
+			if httpRes.Request != nil {
+				attempt = gobackoff.AttemptFromContext(httpRes.Request.Context())
+			}
+
+			return fun(httpRes, attempt, val)
+		}
+	}
+}
+
+// WithDecodeOnError configures a Request to decode the response body even if the HTTP response status code is
+// outside the 2xx range. In that case, Do returns both the decoded response and a *ResponseError.
+func WithDecodeOnError[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.decodeOnError = true
+	}
+}
+
+// WithErrorOnNullResult configures a Request to return a *NullResultError if the response body has been decoded
+// successfully, but the decoded value is nil. This applies if Res is a pointer, map, slice, or interface type,
+// and the response body is JSON null.
+func WithErrorOnNullResult[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.errorOnNullResult = true
+	}
+}
+
+// WithRejectUnknownFields configures a Request to fail decoding the response body with a *DecodeError if it
+// contains object members that do not correspond to a field of the decoded value. The error names the unknown
+// member. This helps detecting changes of the server's API early.
+//
+// WithRejectUnknownFields has no effect if the Request has been configured using WithUnmarshalResponseFunc.
+func WithRejectUnknownFields[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.unmarshalOptions = append(req.unmarshalOptions, json.RejectUnknownMembers(true))
+	}
+}
+
+// WithRequestNilSliceAsEmpty configures a Request to encode nil slices in the request data as empty JSON arrays
+// rather than null, overriding any options configured using WithDefaultMarshalOptions. Fields tagged with omitempty
+// are omitted either way. Since empty arrays are the default of the JSON encoder, this is only necessary if
+// the client has been configured to encode nil slices as null, for example using json.FormatNilSliceAsNull.
+//
+// WithRequestNilSliceAsEmpty has no effect if the Request has been configured using WithMarshalRequestFunc.
+func WithRequestNilSliceAsEmpty[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.marshalOptions = append(req.marshalOptions, json.FormatNilSliceAsNull(false))
+	}
+}
+
+// WithRequestNilSliceAsNull configures a Request to encode nil slices in the request data as JSON null rather than
+// empty arrays, overriding any options configured using WithDefaultMarshalOptions. Fields tagged with omitempty
+// are omitted either way.
+//
+// WithRequestNilSliceAsNull has no effect if the Request has been configured using WithMarshalRequestFunc.
+func WithRequestNilSliceAsNull[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.marshalOptions = append(req.marshalOptions, json.FormatNilSliceAsNull(true))
+	}
+}
+
+// WithIgnoreResponseBody configures a Request to ignore the response body, regardless of status code.
+// The response body will always be ignored if the status code is http.StatusNoContent or http.StatusNotModified.
+func WithIgnoreResponseBody[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.ignoreResponseBody = true
+	}
+}
+
+// Do executes req with client and returns the response.
+//
+// If the request data is nil, the request will be made without a body.
+// If the response status code is http.StatusNoContent or http.StatusNotModified, or the response body should be ignored,
+// Response.Res will be the default value of Res. The same applies to redirect responses that have not been followed
+// because of the client's redirect policy (see WithRedirectPolicy).
+//
+// If an HTTP request fails, it is retried using backoff according to the retry function, up to the
+// maximum number of attempts.
+// If the context is canceled, or if the retry function returns a non-nil error, Do stops and returns
+// a gobackoff.AbortError. If the maximum number of attempts has been reached, Do returns a *RetriesExhaustedError
+// that wraps the gobackoff.MaxAttemptsError. If the Client has been configured using WithUnwrapFinalError,
+// the returned error has the message of the error of the last attempt, but still wraps these errors.
+// If the Request has been configured using WithRetryOnEmptyBody, an empty response body is always retried.
+// If the Request has been configured using WithRateLimitError, or the Client has been configured using
+// WithRespectRetryAfter, a new attempt after an http.StatusTooManyRequests response is additionally delayed
+// as requested by the server.
+// If ctx has a deadline that would be reached before such a delay elapses, Do stops immediately and returns
+// a gobackoff.AbortError that wraps a *RetryDeadlineError, instead of waiting only to fail. The delays of
+// the backoff itself are not checked, since they cannot be determined in advance.
+//
+// If the Request has been configured using WithDecodeOnError, Do may return both a response and an error.
+//
+// If the Client has been configured using WithRequestCoalescing, concurrent calls for requests with the same
+// idempotency key share a single result.
+//
+// Do is safe to call concurrently with the same Request, unless it has been configured using WithMultipartBody
+// with parts that do not use Part.Open, or using WithRawRequestBody.
+func Do[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res]) (*Response[Res], error) {
+	var (
+		res  *Response[Res]
+		info *FinalizeInfo
+		err  error
+	)
+
+	if client.coalescer != nil && req.idempotencyKey != "" {
+		res, info, err = coalesce(ctx, client, req, func() (*Response[Res], *FinalizeInfo, error) {
+			return doWithRetries(ctx, client, req)
+		})
+	} else {
+		res, info, err = doWithRetries(ctx, client, req)
+	}
+
+	notifyFinalizers(ctx, client, info)
+
+	return res, err
+}
+
+// doWithRetries executes req using client, retrying it as necessary. It also returns the information to pass to
+// the client's finalizers, which is nil if the client has no finalizers or has been shut down.
+func doWithRetries[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res],
+) (*Response[Res], *FinalizeInfo, error) {
+	var (
+		res         *Response[Res]
+		lastAttempt int
+		lastHTTPRes *http.Response
+		lastErr     error
+	)
+
+	if err := client.acquire(); err != nil {
+		return nil, nil, err
+	}
+
+	defer client.release()
+
+	firstStart := client.clock.Now()
+	jitter := client.newJitterState()
+	maxAttempts := requestMaxAttempts(client, req)
+
+	ctx = withMarshalOnce(ctx, req)
+
+	err := client.backoff.Do(ctx, func(ctx context.Context) error {
+		var (
+			httpReq *http.Request
+			httpRes *http.Response
+			err     error
+		)
+
+		if client.circuitBreaker != nil && !client.circuitBreaker.Allow() {
+			return &gobackoff.AbortError{
+				Err: ErrCircuitOpen,
+			}
+		}
+
+		attemptStart := client.clock.Now()
+
+		res, httpReq, httpRes, err = do(ctx, client, req) //nolint:bodyclose // body is already closed
+
+		lastAttempt, lastHTTPRes, lastErr = gobackoff.AttemptFromContext(ctx), httpRes, err
+
+		notifyAttempt(ctx, client, req, httpRes, err, client.clock.Now().Sub(attemptStart))
+		recordCircuitBreaker(client.circuitBreaker, err)
+
+		var tooLargeErr *RequestTooLargeError
+		if errors.Is(err, context.Canceled) || errors.As(err, &tooLargeErr) {
+			return &gobackoff.AbortError{
+				Err: err,
+			}
+		}
+
+		if errors.Is(err, ErrEmptyBody) {
+			return err
+		}
+
+		if retryErr := client.retryFunc(ctx, newRetryInfo(ctx, firstStart, client.clock.Now(), httpReq, httpRes, err)); retryErr != nil {
+			return &gobackoff.AbortError{
+				Err: retryErr,
+			}
+		}
+
+		if err != nil && gobackoff.AttemptFromContext(ctx) < maxAttempts {
+			if sleepErr := retrySleep(ctx, client.clock, max(retryDelay(client, httpRes, err), jitter.next()), err); sleepErr != nil {
+				return &gobackoff.AbortError{
+					Err: sleepErr,
+				}
+			}
+		}
+
+		return err
+	}, maxAttempts)
+
+	if res != nil {
+		res.Attempts = lastAttempt
+
+		if req.responseMeta {
+			meta := newResponseMeta(res)
+			res.Meta = &meta
+		}
+	}
+
+	if _, ok := err.(*gobackoff.MaxAttemptsError); ok { //nolint:errorlint // must be the exact type returned by gobackoff
+		err = newRetriesExhaustedError(err, lastAttempt, lastHTTPRes, lastErr)
+	}
+
+	if err != nil && client.unwrapFinalError {
+		err = unwrapBackoffError(err)
+	}
+
+	info := newFinalizeInfo(client, req, lastHTTPRes, lastAttempt, client.clock.Now().Sub(firstStart), err)
+
+	if err != nil {
+		return res, info, err //nolint:wrapcheck // we don't add new info here
+	}
+
+	return res, info, nil
+}
+
+// DoInto executes req with client in the same way as Do, but decodes the response body into out instead of
+// a new value of Res. This allows callers to reuse values across requests to reduce allocations.
+// Response.Res is set to the value of out after decoding.
+//
+// out is not reset before decoding, so fields that are not present in the response body keep their previous
+// values. If the response body is not decoded, for example if the response status code is http.StatusNoContent,
+// out is left untouched, and Response.Res is the default value of Res. If decoding fails, out may have been
+// modified partially, even if the request is then retried successfully.
+func DoInto[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res], out *Res,
+) (*Response[Res], error) {
+	if out == nil {
+		panic("out must not be nil")
+	}
+
+	intoReq := *req
+	intoReq.decodeInto = out
+
+	return Do(ctx, client, &intoReq)
+}
+
+func newRetriesExhaustedError(err error, attempts int, lastHTTPRes *http.Response, lastErr error) *RetriesExhaustedError {
+	exhaustedErr := RetriesExhaustedError{
+		Attempts: attempts,
+		Err:      err,
+	}
+
+	if lastHTTPRes != nil {
+		exhaustedErr.LastStatusCode = lastHTTPRes.StatusCode
+		exhaustedErr.LastStatus = lastHTTPRes.Status
+	}
+
+	var (
+		resErr         *ResponseError
+		decodeErr      *DecodeError
+		contentTypeErr *UnexpectedContentTypeError
+	)
+
+	switch {
+	case errors.As(lastErr, &resErr):
+		exhaustedErr.LastBody = resErr.Body
+
+	case errors.As(lastErr, &decodeErr):
+		exhaustedErr.LastBody = decodeErr.RawBody
+
+	case errors.As(lastErr, &contentTypeErr):
+		exhaustedErr.LastBody = contentTypeErr.Body
+	}
+
+	return &exhaustedErr
+}
+
+func newRetryInfo(ctx context.Context, start time.Time, now time.Time, httpReq *http.Request, httpRes *http.Response,
+	err error,
+) RetryInfo {
+	return RetryInfo{
+		Attempt:  gobackoff.AttemptFromContext(ctx),
+		Start:    start,
+		Elapsed:  now.Sub(start),
+		Request:  httpReq,
+		Response: httpRes,
+		Err:      err,
+	}
+}
+
+// unwrapBackoffError returns a *finalError for the error wrapped by err if err is a *RetriesExhaustedError,
+// *gobackoff.MaxAttemptsError, or *gobackoff.AbortError. Otherwise, err is returned as is.
+func unwrapBackoffError(err error) error {
+	backoffErr := err
+	if exhaustedErr, ok := err.(*RetriesExhaustedError); ok { //nolint:errorlint // must be the exact type returned by Do
+		backoffErr = exhaustedErr.Err
+	}
+
+	var cause error
+
+	switch backoffErr := backoffErr.(type) { //nolint:errorlint // must be the exact types returned by gobackoff
+	case *gobackoff.MaxAttemptsError:
+		cause = backoffErr.Err
+
+	case *gobackoff.AbortError:
+		cause = backoffErr.Err
+	}
+
+	if cause == nil {
+		return err
+	}
+
+	return &finalError{
+		cause:   cause,
+		wrapper: err,
+	}
+}
+
+// requestMaxAttempts returns the maximum number of attempts for req.
+func requestMaxAttempts[Req any, Res any](client *Client, req *Request[Req, Res]) int {
+	if client.idempotentOnly && !req.idempotent && !isIdempotent(req.method) {
+		return 1
+	}
+
+	return client.maxAttempts
+}
+
+// requestDecompression returns whether the response body of req should be decompressed.
+func requestDecompression[Req any, Res any](client *Client, req *Request[Req, Res]) bool {
+	switch req.decompression {
+	case decompressionEnabled:
+		return true
+	case decompressionDisabled:
+		return false
+	default:
+		return client.decompression
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// defaultRateLimitMaxDelay is the maximum delay requested by the server using a *RateLimitError that is honored
+// if the Client has not been configured using WithRespectRetryAfter.
+const defaultRateLimitMaxDelay = time.Minute
+
+// retryDelay returns the additional time to wait before making another attempt, as requested by the server.
+func retryDelay(client *Client, httpRes *http.Response, err error) time.Duration {
+	var delay time.Duration
+
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		delay = rateLimitErr.delay(client.clock.Now())
+	}
+
+	if client.retryAfterMaxDelay <= 0 {
+		return min(delay, defaultRateLimitMaxDelay)
+	}
+
+	if delay == 0 && httpRes != nil &&
+		(httpRes.StatusCode == http.StatusTooManyRequests || httpRes.StatusCode == http.StatusServiceUnavailable) {
+		delay, _ = parseRetryAfter(httpRes.Header.Get("Retry-After"), client.clock.Now())
+	}
+
+	return min(delay, client.retryAfterMaxDelay)
+}
+
+func notifyAttempt[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res], httpRes *http.Response,
+	err error, duration time.Duration,
+) {
+	if len(client.onAttempt) == 0 {
+		return
+	}
+
+	info := AttemptInfo{
+		Attempt:  gobackoff.AttemptFromContext(ctx),
+		Method:   req.method,
+		URI:      requestURI(client, req),
+		Duration: duration,
+		Err:      err,
+	}
+
+	if httpRes != nil {
+		info.StatusCode = httpRes.StatusCode
+	}
+
+	info.Class = ClassifyError(err, info.StatusCode)
+
+	for _, fun := range client.onAttempt {
+		fun(ctx, &info)
+	}
+}
+
+func do[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res],
+) (*Response[Res], *http.Request, *http.Response, error) {
+	httpReq, err := newHTTPRequest(ctx, client, req)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("new HTTP request: %w", err)
+	}
+
+	attempt := gobackoff.AttemptFromContext(ctx)
+
+	client.logger.InfoContext(ctx, "execute HTTP request",
+		slog.Group("request",
+			slog.String("uri", httpReq.URL.String()),
+			slog.String("method", httpReq.Method),
+		),
+		slog.Int("attempt", attempt),
+	)
+
+	if client.logBodiesMaxLength > 0 {
+		logRequestBody(ctx, client, httpReq)
+	}
+
+	if client.debugDump != nil {
+		dumpRequest(ctx, client, httpReq)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, client.requestTimeout) //nolint:ineffassign,staticcheck // better be safe than sorry
+	defer cancel()
+
+	if req.beforeSend != nil {
+		if err = req.beforeSend(httpReq); err != nil {
+			return nil, httpReq, nil, fmt.Errorf("before send: %w", err)
+		}
+	}
+
+	sendStart := client.clock.Now()
+
+	httpRes, err := executeHTTPRequest(client, httpReq, req.method, requestURI(client, req))
+	if err != nil {
+		return nil, httpReq, httpRes, fmt.Errorf("execute HTTP request: %w", err)
+	}
+
+	duration := client.clock.Now().Sub(sendStart)
+
+	body := &trackedBody{body: httpRes.Body}
+	httpRes.Body = body
+
+	defer httpRes.Body.Close() //nolint:errcheck // we're only reading
+
+	assumeContentType(httpRes, client.assumeContentType)
+
+	for _, m := range client.responseMiddlewares {
+		if err = m(httpRes); err != nil {
+			return nil, httpReq, httpRes, fmt.Errorf("response middleware: %w", err)
+		}
+	}
+
+	if client.debugDump != nil {
+		dumpResponse(client, httpRes)
+	}
+
+	errorStatus := !isSuccess(httpRes.StatusCode) && httpRes.StatusCode != http.StatusNotModified && !isRedirect(httpRes.StatusCode) &&
+		(httpRes.StatusCode != http.StatusTooManyRequests || !req.rateLimitError)
+
+	if errorStatus && client.responseErrors && !req.decodeOnError {
+		return nil, httpReq, httpRes, newResponseError(httpRes, client.maxResponseBodySize)
+	}
+
+	decodeRes := httpRes
+
+	if requestDecompression(client, req) {
+		if decodeRes, err = decompress(httpRes); err != nil {
+			return nil, httpReq, httpRes, fmt.Errorf("decompress response: %w", err)
+		}
+	}
+
+	var limitedBody *limitedBody
+
+	if client.maxResponseBodySize > 0 {
+		decodeRes, limitedBody = limitResponseBody(decodeRes, client.maxResponseBodySize)
+	}
+
+	if client.logBodiesMaxLength > 0 {
+		decodeRes = logResponseBody(ctx, client, decodeRes)
+	}
+
+	if req.conditionalGet {
+		switch {
+		case httpRes.StatusCode == http.StatusNotModified:
+			if res, ok, err := cachedResponse(client, httpReq, decodeRes, req); ok {
+				if res != nil {
+					res.Duration = duration
+				}
+
+				return res, httpReq, httpRes, err
+			}
+
+		case isSuccess(httpRes.StatusCode):
+			if decodeRes, err = cacheResponse(client.responseCache, httpReq, decodeRes, limitedBody); err != nil {
+				return nil, httpReq, httpRes, fmt.Errorf("cache response: %w", err)
+			}
+		}
+	}
+
+	if errorStatus && req.decodeOnError {
+		res, err := errorResponse(client, decodeRes, req)
+		if res != nil {
+			res.Duration = duration
+			res.Trailer = responseTrailer(httpRes)
+			res.bodyBytes = body.read
+		}
+
+		return res, httpReq, httpRes, connectionClosed(body, err)
+	}
+
+	res, err := response(client, decodeRes, req)
+
+	if limitedBody != nil && limitedBody.exceeded {
+		return nil, httpReq, httpRes, fmt.Errorf("get response: %w", &ResponseBodyTooLargeError{Limit: client.maxResponseBodySize})
+	}
+
+	if err != nil {
+		return nil, httpReq, httpRes, fmt.Errorf("get response: %w", connectionClosed(body, err))
+	}
+
+	res.Duration = duration
+	res.Trailer = responseTrailer(httpRes)
+	res.bodyBytes = body.read
+
+	return res, httpReq, httpRes, nil
+}
+
+// maxTrailerDrain is the maximum number of unread response body bytes that responseTrailer reads to receive
+// the trailers.
+const maxTrailerDrain = 64 * 1024
+
+// responseTrailer returns the trailers of httpRes. Since trailers are only received after the response body has been
+// read completely, the remaining response body is discarded first, up to maxTrailerDrain bytes.
+func responseTrailer(httpRes *http.Response) http.Header {
+	if _, err := io.CopyN(io.Discard, httpRes.Body, maxTrailerDrain); !errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	if len(httpRes.Trailer) == 0 {
+		return nil
+	}
+
+	return httpRes.Trailer
+}
+
+// errorResponse decodes the body of an error response and returns it together with a *ResponseError.
+func errorResponse[Req any, Res any](client *Client, httpRes *http.Response, req *Request[Req, Res]) (*Response[Res], error) {
+	body, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	resErr := ResponseError{
+		StatusCode: httpRes.StatusCode,
+		Status:     httpRes.Status,
+		Body:       body,
+	}
+
+	bufferedRes := *httpRes
+	bufferedRes.Body = io.NopCloser(bytes.NewReader(body))
+
+	res, err := response(client, &bufferedRes, req)
+	if err != nil {
+		return nil, &resErr
+	}
+
+	return res, &resErr
+}
+
+// modifyHTTPRequest calls fun to modify httpReq and makes sure that the body of the returned request is replayable.
+func modifyHTTPRequest(httpReq *http.Request, fun RequestModifierFunc) (*http.Request, error) {
+	httpReq, err := fun(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpReq.Body == nil || httpReq.Body == http.NoBody || httpReq.GetBody != nil {
+		return httpReq, nil
+	}
+
+	body, err := io.ReadAll(httpReq.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+
+	_ = httpReq.Body.Close()
+
+	httpReq.Body = io.NopCloser(bytes.NewReader(body))
+	httpReq.ContentLength = int64(len(body))
+
+	httpReq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return httpReq, nil
+}
+
+func sendRequestBody[Req any, Res any](client *Client, req *Request[Req, Res]) bool {
+	switch req.sendBody {
+	case sendBodyAlways:
+		return true
+	case sendBodyOmit:
+		return false
+	default:
+		return !slices.Contains(client.bodylessMethods, req.method)
+	}
+}
+
+// withMarshalOnce returns a context that carries the state of WithMarshalOnce for a single call to Do,
+// if req has been configured using WithMarshalOnce. Otherwise, ctx is returned as is.
+func withMarshalOnce[Req any, Res any](ctx context.Context, req *Request[Req, Res]) context.Context {
+	if !req.marshalOnce {
+		return ctx
+	}
+
+	return context.WithValue(ctx, marshalOnceKey{}, &marshalOnceBody{})
+}
+
+// requestBody returns the encoded request body of req, or nil if no request body should be sent.
+func requestBody[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res]) ([]byte, error) {
+	if !sendRequestBody(client, req) {
+		return nil, nil
+	}
+
+	once, _ := ctx.Value(marshalOnceKey{}).(*marshalOnceBody)
+
+	if once == nil || !once.done {
+		data := req.req
+
+		if req.bodyFunc != nil {
+			var err error
+			if data, err = req.bodyFunc(); err != nil {
+				return nil, fmt.Errorf("body func: %w", err)
+			}
+		}
+
+		var body []byte
+
+		if any(data) != nil {
+			buf := bytes.Buffer{}
+			buf.Grow(req.bufferHint)
+
+			if err := marshalFunc(client, req)(&buf, data); err != nil {
+				return nil, fmt.Errorf("encode request body: %w", err)
+			}
+
+			body = buf.Bytes()
+		}
+
+		if once == nil {
+			return body, nil
+		}
+
+		once.body = body
+		once.done = true
+	}
+
+	return once.body, nil
+}
+
+// marshalFunc returns the marshal function of req, or a function that uses the client's default options
+// if req does not have one.
+func marshalFunc[Req any, Res any](client *Client, req *Request[Req, Res]) MarshalJSONFunc[Req] {
+	if req.marshalRequest != nil {
+		return req.marshalRequest
+	}
+
+	opts := slices.Concat(client.marshalOptions, req.marshalOptions)
+
+	return func(writer io.Writer, val Req) error {
+		return json.MarshalWrite(writer, val, opts...)
+	}
+}
+
+// unmarshalFunc returns the unmarshal function of req, or a function that uses the client's default options
+// if req does not have one.
+func unmarshalFunc[Req any, Res any](client *Client, req *Request[Req, Res]) UnmarshalJSONFunc[Res] {
+	if req.unmarshalResponse != nil {
+		return req.unmarshalResponse
+	}
+
+	opts := unmarshalOptions(client, req)
+
+	return func(httpRes *http.Response, val *Res) error {
+		return json.UnmarshalRead(httpRes.Body, val, opts...)
+	}
+}
+
+// unmarshalOptions returns the client's default unmarshal options, followed by the unmarshal options of req.
+func unmarshalOptions[Req any, Res any](client *Client, req *Request[Req, Res]) []json.Options {
+	return slices.Concat(client.unmarshalOptions, req.unmarshalOptions)
+}
+
+func bodyReader(body []byte) io.Reader {
+	if body == nil {
+		return http.NoBody
+	}
+
+	return bytes.NewReader(body)
+}
+
+// requestURI resolves the URI of req relative to the request's base URI, or to the client's base URI if the request
+// does not have one.
+func requestURI[Req any, Res any](client *Client, req *Request[Req, Res]) string {
+	return resolveURI(cmp.Or(req.baseURI, client.baseURI), appendEscapedPath(req.uri, req.pathSegments))
+}
+
+// appendEscapedPath appends segments to the path of uri, escaping each of them using url.PathEscape.
+func appendEscapedPath(uri string, segments []string) string {
+	if len(segments) == 0 {
+		return uri
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		// let http.NewRequestWithContext report the error
+		return uri
+	}
+
+	escapedPath := strings.TrimSuffix(u.EscapedPath(), "/")
+	for _, seg := range segments {
+		escapedPath += "/" + url.PathEscape(seg)
+	}
+
+	if u.Path, err = url.PathUnescape(escapedPath); err != nil {
+		return uri
+	}
+
+	u.RawPath = escapedPath
+
+	return u.String()
+}
+
+// resolveURI resolves uri relative to baseURI.
+func resolveURI(baseURI string, uri string) string {
+	if baseURI == "" {
+		return uri
+	}
+
+	base, err := url.Parse(baseURI)
+	if err != nil {
+		// let http.NewRequestWithContext report the error
+		return baseURI + uri
+	}
+
+	ref, err := url.Parse(uri)
+	if err != nil {
+		return baseURI + uri
+	}
+
+	if ref.IsAbs() || uri == "" {
+		return cmp.Or(uri, baseURI)
+	}
+
+	if ref.Path != "" && ref.Host == "" {
+		if !strings.HasSuffix(base.Path, "/") {
+			base.Path += "/"
+
+			if base.RawPath != "" {
+				base.RawPath += "/"
+			}
+		}
+
+		ref.Path = strings.TrimLeft(ref.Path, "/")
+		ref.RawPath = strings.TrimLeft(ref.RawPath, "/")
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+func newHTTPRequest[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res]) (*http.Request, error) {
+	var (
+		jsonReqData io.Reader
+		getBody     func() (io.ReadCloser, error)
+		body        []byte
+		err         error
+	)
+
+	contentType := cmp.Or(req.contentType, client.contentType)
+
+	streamed := req.multipartParts != nil || req.rawBody != nil || req.rawBodyOpen != nil
+
+	switch {
+	case req.multipartParts != nil:
+		var multipart *multipartBody
+		if multipart, err = newMultipartBody(req.multipartParts); err != nil {
+			return nil, fmt.Errorf("multipart body: %w", err)
+		}
+
+		jsonReqData = multipart
+		getBody = multipart.getBody()
+		contentType = multipart.contentType()
+
+	case req.rawBody != nil:
+		if jsonReqData, err = rawRequestBody(req.rawBody); err != nil {
+			return nil, err
+		}
+
+		getBody = rawGetBody(req.rawBody)
+
+		contentType = cmp.Or(req.rawBodyContentType, contentType)
+
+	case req.rawBodyOpen != nil:
+		getBody = rawOpenGetBody(req.rawBodyOpen)
+
+		if jsonReqData, err = getBody(); err != nil {
+			return nil, err
+		}
+
+		contentType = cmp.Or(req.rawBodyContentType, contentType)
+
+	default:
+		if body, err = requestBody(ctx, client, req); err != nil {
+			return nil, err
+		}
+
+		if err = checkRequestBodySize(client, body); err != nil {
+			return nil, err
+		}
+
+		jsonReqData = bodyReader(body)
+	}
+
+	for _, val := range req.contextValues {
+		ctx = context.WithValue(ctx, val.key, val.value)
+	}
+
+	if req.informational != nil {
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+				req.informational(code, http.Header(header))
+				return nil
+			},
+		})
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, requestURI(client, req), jsonReqData)
+	if err != nil {
 		return nil, fmt.Errorf("new HTTP request: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json; charset=UTF-8")
-	httpReq.Header.Set("Accept", "application/json")
+	// buffered bodies are made replayable by http.NewRequestWithContext already
+	if httpReq.GetBody == nil {
+		httpReq.GetBody = getBody
+	}
+
+	if len(req.queryParams) != 0 {
+		query := httpReq.URL.Query()
+
+		for key, vals := range req.queryParams {
+			for _, val := range vals {
+				query.Add(key, val)
+			}
+		}
+
+		httpReq.URL.RawQuery = query.Encode()
+	}
+
+	if !streamed {
+		for _, fun := range client.onRequestSize {
+			fun(req.method, httpReq.URL.String(), len(body))
+		}
+	}
+
+	if streamed || len(body) > 0 || (!client.contentTypeOnlyBody && !req.bodyless) {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+
+	httpReq.Header.Set("Accept", cmp.Or(req.accept, client.accept))
+
+	if req.acceptEncoding != "" {
+		httpReq.Header.Set("Accept-Encoding", req.acceptEncoding)
+	}
+
+	if req.idempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", req.idempotencyKey)
+	}
+
+	// copy the values so that middlewares adding to them don't modify req, which may be used concurrently
+	for key, vals := range req.header {
+		httpReq.Header[key] = slices.Clone(vals)
+	}
+
+	for _, cookie := range req.cookies {
+		httpReq.AddCookie(cookie)
+	}
+
+	if req.deadlineHeader != "" {
+		if deadline, ok := ctx.Deadline(); ok {
+			httpReq.Header.Set(req.deadlineHeader, deadlineHeaderValue(req.deadlineHeader, time.Until(deadline)))
+		}
+	}
 
 	for _, m := range client.requestMiddlewares {
 		if err = m(httpReq); err != nil {
@@ -325,27 +2001,350 @@ func newHTTPRequest[Req any, Res any](ctx context.Context, client *Client, req *
 		}
 	}
 
+	for _, m := range client.bodyMiddlewares {
+		if err = m(httpReq, body); err != nil {
+			return nil, fmt.Errorf("body-aware request middleware: %w", err)
+		}
+	}
+
+	if req.requestModifier != nil {
+		if httpReq, err = modifyHTTPRequest(httpReq, req.requestModifier); err != nil {
+			return nil, fmt.Errorf("request modifier: %w", err)
+		}
+	}
+
+	if req.conditionalGet {
+		setIfNoneMatch(client.responseCache, httpReq)
+	}
+
 	return httpReq, nil
 }
 
-func response[Req any, Res any](httpRes *http.Response, req *Request[Req, Res]) (*Response[Res], error) {
-	if httpRes.StatusCode == http.StatusNoContent || req.ignoreResponseBody {
-		return &Response[Res]{
+func response[Req any, Res any](client *Client, httpRes *http.Response, req *Request[Req, Res]) (*Response[Res], error) {
+	if httpRes.StatusCode == http.StatusTooManyRequests && req.rateLimitError {
+		return nil, newRateLimitError(httpRes, client.clock.Now())
+	}
+
+	if err := checkETag(httpRes, req.expectETag); err != nil {
+		return nil, err
+	}
+
+	if req.headerCallback != nil {
+		decode, err := req.headerCallback(httpRes.Header, httpRes.StatusCode)
+		if err != nil {
+			return nil, fmt.Errorf("response header callback: %w", err)
+		}
+
+		if !decode {
+			return newResponse[Res](httpRes, nil), nil
+		}
+	}
+
+	if httpRes.StatusCode == http.StatusNoContent || httpRes.StatusCode == http.StatusNotModified || isRedirect(httpRes.StatusCode) ||
+		req.ignoreResponseBody {
+		return newResponse[Res](httpRes, nil), nil
+	}
+
+	if client.rejectNonJSONErrors && req.responseWriter == nil {
+		if err := checkErrorContentType(httpRes); err != nil {
+			return nil, err
+		}
+	}
+
+	var rawBody []byte
+
+	if req.rawBodyMaxSize > 0 {
+		var err error
+		if httpRes, rawBody, err = captureRawBody(httpRes, req.rawBodyMaxSize, req.responseSizeHint); err != nil {
+			return nil, fmt.Errorf("capture raw body: %w", err)
+		}
+	}
+
+	if req.readerWrapper != nil {
+		reader := req.readerWrapper(httpRes.Body)
+
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close() //nolint:errcheck // we're only reading
+		}
+
+		wrappedRes := *httpRes
+		wrappedRes.Body = io.NopCloser(reader)
+		httpRes = &wrappedRes
+	}
+
+	if req.stripComments {
+		var err error
+		if httpRes, err = stripResponseComments(httpRes); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.responseWriter != nil {
+		written, err := io.Copy(req.responseWriter, httpRes.Body)
+		if err != nil {
+			return nil, fmt.Errorf("write response: %w", err)
+		}
+
+		res := newResponse[Res](httpRes, rawBody)
+		res.BytesWritten = written
+
+		return res, nil
+	}
+
+	if req.emptyBody != emptyBodyDecode {
+		var (
+			empty bool
+			err   error
+		)
+
+		if httpRes, empty, err = peekEmptyBody(httpRes); err != nil {
+			return nil, fmt.Errorf("read response body: %w", err)
+		}
+
+		if empty {
+			if req.emptyBody == emptyBodyRetry {
+				return nil, ErrEmptyBody
+			}
+
+			return newResponse[Res](httpRes, rawBody), nil
+		}
+	}
+
+	if req.responseSchema != nil {
+		var err error
+		if httpRes, err = validateSchema(httpRes, req.responseSchema); err != nil {
+			return nil, err
+		}
+	}
+
+	errorBody := rawBody
+
+	if rawBody == nil && client.decodeErrorBodySize > 0 {
+		var err error
+		if httpRes, errorBody, err = captureRawBody(httpRes, client.decodeErrorBodySize, 0); err != nil {
+			return nil, fmt.Errorf("read response body: %w", err)
+		}
+	}
+
+	jsonRes := decodeTarget(req)
+	if err := unmarshalFunc(client, req)(httpRes, jsonRes); err != nil {
+		return nil, &DecodeError{
+			Err:        err,
+			StatusCode: httpRes.StatusCode,
+			RawBody:    errorBody,
+		}
+	}
+
+	if req.errorOnNullResult && isNil(*jsonRes) {
+		return nil, &NullResultError{
 			StatusCode: httpRes.StatusCode,
 			Status:     httpRes.Status,
-		}, nil
+		}
+	}
+
+	res := newResponse[Res](httpRes, rawBody)
+	res.Res = *jsonRes
+
+	return res, nil
+}
+
+// decodeTarget returns the value that the response body of req should be decoded into.
+func decodeTarget[Req any, Res any](req *Request[Req, Res]) *Res {
+	if req.decodeInto != nil {
+		return req.decodeInto
+	}
+
+	return new(Res)
+}
+
+func newResponse[Res any](httpRes *http.Response, rawBody []byte) *Response[Res] {
+	res := Response[Res]{
+		StatusCode: httpRes.StatusCode,
+		Status:     httpRes.Status,
+		Header:     httpRes.Header,
+		TLS:        httpRes.TLS,
+		RawBody:    rawBody,
+	}
+
+	if httpRes.Request != nil {
+		res.RequestURL = httpRes.Request.URL.String()
+		res.RequestMethod = httpRes.Request.Method
+		res.RedirectChain = redirectChain(httpRes.Request)
+	}
+
+	return &res
+}
+
+// redirectChain returns the URLs of the requests leading up to and including httpReq that were made
+// because of redirects.
+func redirectChain(httpReq *http.Request) []*url.URL {
+	var chain []*url.URL
+
+	for req := httpReq; req != nil && req.Response != nil; req = req.Response.Request {
+		chain = append(chain, req.URL)
+	}
+
+	slices.Reverse(chain)
+
+	return chain
+}
+
+func deadlineHeaderValue(header string, remaining time.Duration) string {
+	millis := strconv.FormatInt(max(remaining.Milliseconds(), 0), 10)
+
+	if strings.EqualFold(header, "grpc-timeout") {
+		return millis + "m"
+	}
+
+	return millis
+}
+
+// decompress returns a copy of httpRes whose body yields the decompressed response body if the response
+// has a Content-Encoding of gzip. Otherwise, httpRes is returned as is.
+func decompress(httpRes *http.Response) (*http.Response, error) {
+	if !strings.EqualFold(httpRes.Header.Get("Content-Encoding"), "gzip") {
+		return httpRes, nil
+	}
+
+	reader, err := gzip.NewReader(httpRes.Body)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // we don't add new info here
+	}
+
+	decompressedRes := *httpRes
+	decompressedRes.Body = io.NopCloser(reader)
+	decompressedRes.Header = httpRes.Header.Clone()
+	decompressedRes.Header.Del("Content-Encoding")
+	decompressedRes.Header.Del("Content-Length")
+	decompressedRes.ContentLength = -1
+	decompressedRes.Uncompressed = true
+
+	return &decompressedRes, nil
+}
+
+// assumeContentType sets the Content-Type header of httpRes to contentType if httpRes has a body
+// but no Content-Type header.
+func assumeContentType(httpRes *http.Response, contentType string) {
+	if contentType == "" || httpRes.ContentLength == 0 || httpRes.Header.Get("Content-Type") != "" {
+		return
 	}
 
-	var jsonRes Res
-	if err := req.unmarshalResponse(httpRes, &jsonRes); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	if httpRes.Header == nil {
+		httpRes.Header = http.Header{}
+	}
+
+	httpRes.Header.Set("Content-Type", contentType)
+}
+
+// newResponseError returns a *ResponseError for httpRes. If maxBodySize is greater than 0, at most maxBodySize
+// bytes of the response body are read.
+func newResponseError(httpRes *http.Response, maxBodySize int64) *ResponseError {
+	var bodyReader io.Reader = httpRes.Body
+	if maxBodySize > 0 {
+		bodyReader = io.LimitReader(httpRes.Body, maxBodySize)
 	}
 
-	return &Response[Res]{
-		Res:        jsonRes,
+	body, _ := io.ReadAll(bodyReader)
+
+	return &ResponseError{
 		StatusCode: httpRes.StatusCode,
 		Status:     httpRes.Status,
-	}, nil
+		Body:       body,
+	}
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
+
+// isNil returns true if val is nil, or a nil pointer, map, slice, or interface.
+func isNil(val any) bool {
+	if val == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(val)
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return value.IsNil()
+
+	default:
+		return false
+	}
+}
+
+// isRedirect returns true if statusCode is a redirect that the HTTP client would normally follow.
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect:
+		return true
+
+	default:
+		return false
+	}
+}
+
+// peekEmptyBody determines whether httpRes.Body is empty and returns a copy of httpRes whose body
+// yields the full response body again.
+func peekEmptyBody(httpRes *http.Response) (*http.Response, bool, error) {
+	buf := make([]byte, 1)
+
+	n, err := io.ReadFull(httpRes.Body, buf)
+	if errors.Is(err, io.EOF) {
+		return httpRes, true, nil
+	}
+
+	if err != nil {
+		return nil, false, err //nolint:wrapcheck // we don't add new info here
+	}
+
+	peekedRes := *httpRes
+	peekedRes.Body = io.NopCloser(io.MultiReader(bytes.NewReader(buf[:n]), httpRes.Body))
+
+	return &peekedRes, false, nil
+}
+
+// captureRawBody reads up to maxSize bytes from httpRes.Body and returns a copy of httpRes whose body
+// yields the full response body again. If sizeHint is greater than 0, the buffer is preallocated accordingly.
+func captureRawBody(httpRes *http.Response, maxSize int, sizeHint int64) (*http.Response, []byte, error) {
+	rawBody, err := readAll(io.LimitReader(httpRes.Body, int64(maxSize)), min(sizeHint, int64(maxSize)))
+	if err != nil {
+		return nil, nil, err //nolint:wrapcheck // we don't add new info here
+	}
+
+	capturedRes := *httpRes
+	capturedRes.Body = io.NopCloser(io.MultiReader(bytes.NewReader(rawBody), httpRes.Body))
+
+	return &capturedRes, rawBody, nil
+}
+
+// readAll reads from reader until EOF in the same way as io.ReadAll, but preallocates a buffer of size bytes.
+func readAll(reader io.Reader, size int64) ([]byte, error) {
+	if size <= 0 {
+		return io.ReadAll(reader) //nolint:wrapcheck // we don't add new info here
+	}
+
+	// one more byte so that EOF can be detected without growing the buffer
+	buf := make([]byte, 0, size+1)
+
+	for {
+		n, err := reader.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+
+		if errors.Is(err, io.EOF) {
+			return buf, nil
+		}
+
+		if err != nil {
+			return buf, err //nolint:wrapcheck // we don't add new info here
+		}
+
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+	}
 }
 
 // BasicAuth returns a request middleware that sets the request's Authorization header to use
@@ -367,7 +2366,66 @@ func BearerAuth(token string) RequestMiddlewareFunc {
 	}
 }
 
+// PropagateTraceContext returns a request middleware that injects the W3C trace context (traceparent and
+// tracestate headers) and W3C baggage (baggage header) found in the request's context, as populated
+// by OpenTelemetry.
+func PropagateTraceContext() RequestMiddlewareFunc {
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+	return func(req *http.Request) error {
+		propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+		return nil
+	}
+}
+
 // Error implements error.
 func (e httpError) Error() string {
 	return string(e)
 }
+
+// Error implements error.
+func (e *ResponseError) Error() string {
+	return "HTTP error: " + e.Status
+}
+
+// Error implements error.
+func (e *finalError) Error() string {
+	return e.cause.Error()
+}
+
+// Unwrap returns the error of the last attempt and the error wrapping it.
+func (e *finalError) Unwrap() []error {
+	return []error{e.cause, e.wrapper}
+}
+
+// Error implements error.
+func (e *RetriesExhaustedError) Error() string {
+	return fmt.Sprintf("retries exhausted after %d attempts: %s", e.Attempts, e.Err)
+}
+
+// Unwrap returns e.Err.
+func (e *RetriesExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// Error implements error.
+func (e *NullResultError) Error() string {
+	return "null response result: " + e.Status
+}
+
+// Error implements error.
+func (e *DecodeError) Error() string {
+	msg := "decode response: " + e.Err.Error()
+
+	if len(e.RawBody) > 0 {
+		snippet := e.RawBody[:min(len(e.RawBody), maxDecodeErrorSnippet)]
+		msg += " (body: " + strconv.Quote(string(snippet)) + ")"
+	}
+
+	return msg
+}
+
+// Unwrap returns e.Err.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
@@ -1,17 +1,26 @@
 package gojsonclient
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/blizzy78/gobackoff"
 	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
 	"github.com/matryer/is"
+	"go.opentelemetry.io/otel/baggage"
 )
 
 type testReq struct {
@@ -110,263 +119,2179 @@ func TestDo_Unmarshal(t *testing.T) {
 	_, _ = Do(context.Background(), client, req)
 }
 
-func TestDo_Method(t *testing.T) {
+func TestWithDefaultMarshalOptions(t *testing.T) {
 	is := is.New(t)
 
-	reqData := testReq{
-		Message: "Hello, server!",
-	}
+	var bodies []string
 
 	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
-		is.Equal(req.Method, http.MethodPost)
+		data, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(data))
 
 		http.Error(writer, "No Content", http.StatusNoContent)
 	}))
 
 	defer server.Close()
 
-	client := New()
+	client := New(WithDefaultMarshalOptions(jsontext.WithIndent("  ")))
 
-	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &reqData)
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: "Hello, server!"})
 
-	_, _ = Do(context.Background(), client, req)
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	req = NewRequest(server.URL, http.MethodPost, &testReq{Message: "Hello, server!"},
+		WithMarshalRequestFunc[*testReq, *testRes](func(writer io.Writer, val *testReq) error {
+			return json.MarshalWrite(writer, val)
+		}),
+	)
+
+	_, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(bodies, []string{"{\n  \"message\": \"Hello, server!\"\n}", `{"message":"Hello, server!"}`})
 }
 
-func TestWithBaseURI(t *testing.T) {
+func TestWithRequestNilSliceAsEmpty(t *testing.T) {
 	is := is.New(t)
 
-	reqData := testReq{
-		Message: "Hello, server!",
+	type sliceReq struct {
+		Tags  []string `json:"tags"`
+		Other []string `json:"other,omitempty"`
 	}
 
-	resData := testRes{
-		Reply: "Hello, client!",
-	}
+	client := New(WithDefaultMarshalOptions(json.FormatNilSliceAsNull(true)))
 
-	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
-		is.Equal(req.URL.Path, "/foo")
+	tests := []struct {
+		name string
+		opts []RequestOpt[*sliceReq, any]
+		want string
+	}{
+		{"client default", nil, `{"tags":null}`},
+		{"empty", []RequestOpt[*sliceReq, any]{WithRequestNilSliceAsEmpty[*sliceReq, any]()}, `{"tags":[]}`},
+		{"null", []RequestOpt[*sliceReq, any]{WithRequestNilSliceAsNull[*sliceReq, any]()}, `{"tags":null}`},
+	}
 
-		_ = json.MarshalWrite(writer, &resData)
-	}))
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is.New(t)
 
-	defer server.Close()
+			req := NewRequest("http://example.com", http.MethodPost, &sliceReq{}, test.opts...)
 
-	client := New(WithBaseURI(server.URL))
+			var buf bytes.Buffer
+			is.NoErr(marshalFunc(client, req)(&buf, req.req))
+			is.Equal(buf.String(), test.want)
+		})
+	}
 
-	req := NewRequest[*testReq, *testRes]("/foo", http.MethodGet, &reqData)
+	req := NewRequest("http://example.com", http.MethodPost, &sliceReq{}, WithRequestNilSliceAsNull[*sliceReq, any]())
 
-	_, _ = Do(context.Background(), client, req)
+	var buf bytes.Buffer
+	is.NoErr(marshalFunc(New(), req)(&buf, req.req))
+	is.Equal(buf.String(), `{"tags":null}`)
 }
 
-func TestDo_Retry(t *testing.T) {
+func TestWithDefaultUnmarshalOptions(t *testing.T) {
 	is := is.New(t)
 
-	reqData := testReq{
-		Message: "Hello, server!",
-	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"reply":"Hello, client!","extra":true}`))
+	}))
 
-	attempts := 0
+	defer server.Close()
 
-	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
-		attempts++
+	client := New(
+		WithMaxAttempts(1),
+		WithDefaultUnmarshalOptions(json.RejectUnknownMembers(true)),
+	)
 
-		if attempts == 1 {
-			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
 
-		http.Error(writer, "No Content", http.StatusNoContent)
+	_, err := Do(context.Background(), client, req)
+
+	var decodeErr *DecodeError
+	is.True(errors.As(err, &decodeErr))
+
+	req = NewRequest(server.URL, http.MethodGet, nil,
+		WithUnmarshalResponseFunc[*testReq](func(httpRes *http.Response, val **testRes) error {
+			return json.UnmarshalRead(httpRes.Body, val)
+		}),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+}
+
+func TestDo_UnmarshalAttempt(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_ = json.MarshalWrite(writer, &testRes{Reply: "Hello, client!"})
 	}))
 
 	defer server.Close()
 
-	client := New(withInstantBackoff())
+	client := New(WithClock(newFakeClock()))
 
-	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, &reqData)
+	var attempts []int
+
+	req := NewRequest(server.URL, http.MethodGet, (*testReq)(nil),
+		WithUnmarshalResponseFuncAttempt[*testReq](func(_ *http.Response, attempt int, _ **testRes) error {
+			attempts = append(attempts, attempt)
+
+			if attempt < 3 {
+				return errors.New("not yet") //nolint:goerr113 // dynamic error is okay here
+			}
+
+			return nil
+		}),
+	)
 
 	_, err := Do(context.Background(), client, req)
 	is.NoErr(err)
 
-	is.Equal(attempts, 2)
+	is.Equal(attempts, []int{1, 2, 3})
 }
 
-func TestDo_RetryFunc(t *testing.T) {
+func TestDo_Method(t *testing.T) {
 	is := is.New(t)
 
 	reqData := testReq{
 		Message: "Hello, server!",
 	}
 
-	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.Method, http.MethodPost)
 
-	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
-		attempts++
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
 
-		if attempts == 1 {
-			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
+	defer server.Close()
 
-		http.Error(writer, "No Content", http.StatusNoContent)
+	client := New()
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &reqData)
+
+	_, _ = Do(context.Background(), client, req)
+}
+
+func TestDo_ResponseWriter(t *testing.T) {
+	is := is.New(t)
+
+	png := []byte("\x89PNG\r\n\x1a\n")
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.Header.Get("Accept"), "image/png")
+
+		writer.Header().Set("Content-Type", "image/png")
+		_, _ = writer.Write(png)
 	}))
 
 	defer server.Close()
 
-	client := New(
-		withInstantBackoff(),
+	client := New()
 
-		WithRetry(func(_ context.Context, _ *http.Response, _ error) error {
+	buf := bytes.Buffer{}
+
+	req := NewRequest[any, any](server.URL, http.MethodGet, nil,
+		WithAccept[any, any]("image/png"),
+		WithResponseWriter[any, any](&buf),
+
+		WithUnmarshalResponseFunc[any](func(_ *http.Response, _ *any) error {
+			is.Fail()
 			return nil
 		}),
 	)
 
-	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, &reqData)
-
-	_, err := Do(context.Background(), client, req)
+	res, err := Do(context.Background(), client, req)
 	is.NoErr(err)
-
-	is.Equal(attempts, 2)
+	is.Equal(res.Res, nil)
+	is.Equal(buf.Bytes(), png)
+	is.Equal(res.BytesWritten, int64(len(png)))
 }
 
-func TestDo_RetryFunc_Abort(t *testing.T) {
+func TestDo_AssumeContentType(t *testing.T) {
 	is := is.New(t)
 
-	reqData := testReq{
-		Message: "Hello, server!",
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		// prevent content type sniffing
+		writer.Header()["Content-Type"] = nil
+
+		_, _ = writer.Write([]byte(`{"reply":"Hello, client!"}`))
+	}))
+
+	defer server.Close()
+
+	unmarshal := func(httpRes *http.Response, val **testRes) error {
+		if httpRes.Header.Get("Content-Type") != "application/json" {
+			return errors.New("unexpected content type") //nolint:goerr113 // dynamic error is okay here
+		}
+
+		return json.UnmarshalRead(httpRes.Body, val)
 	}
 
+	req := NewRequest(server.URL, http.MethodGet, (*testReq)(nil),
+		WithUnmarshalResponseFunc[*testReq](unmarshal),
+	)
+
+	res, err := Do(context.Background(), New(WithClock(newFakeClock())), req)
+	is.NoErr(err)
+	is.Equal(res.Header.Get("Content-Type"), "application/json")
+	is.Equal(res.Res.Reply, "Hello, client!")
+
+	_, err = Do(context.Background(), New(WithClock(newFakeClock()), WithMaxAttempts(1), WithAssumeContentType("")), req)
+
+	var decodeErr *DecodeError
+	is.True(errors.As(err, &decodeErr))
+}
+
+func TestDo_ResponseMiddleware(t *testing.T) {
+	is := is.New(t)
+
 	attempts := 0
 
 	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
 		attempts++
 
-		if attempts == 1 {
-			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-
+		writer.Header().Set("X-Attempt", strconv.Itoa(attempts))
 		http.Error(writer, "No Content", http.StatusNoContent)
 	}))
 
 	defer server.Close()
 
-	httpErr := errors.New("HTTP error") //nolint:goerr113 // dynamic error is okay here
+	var calls []string
 
 	client := New(
-		withInstantBackoff(),
+		WithClock(newFakeClock()),
 
-		WithRetry(func(_ context.Context, httpRes *http.Response, _ error) error {
-			if httpRes.StatusCode < 200 || httpRes.StatusCode >= 300 {
-				return httpErr
+		WithResponseMiddleware(func(res *http.Response) error {
+			calls = append(calls, "first")
+
+			if res.Header.Get("X-Attempt") == "1" {
+				return errors.New("first attempt") //nolint:goerr113 // dynamic error is okay here
 			}
 
 			return nil
 		}),
+
+		WithResponseMiddleware(func(_ *http.Response) error {
+			calls = append(calls, "second")
+			return nil
+		}),
 	)
 
-	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, &reqData)
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
 
 	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
 
-	abortErr, ok := err.(*gobackoff.AbortError) //nolint:errorlint // must be *gobackoff.AbortError
-	is.True(ok)
-	is.Equal(abortErr.Err, httpErr)
+	is.Equal(attempts, 2)
+	is.Equal(calls, []string{"first", "first", "second"})
 }
 
-func TestDo_RetryMaxAttempts(t *testing.T) {
+func TestDo_IfNoneMatch(t *testing.T) {
 	is := is.New(t)
 
-	reqData := testReq{
-		Message: "Hello, server!",
-	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.Method, http.MethodGet)
+		is.Equal(req.Header.Get("If-None-Match"), `"abc"`)
 
-	attempts := 0
+		data, _ := io.ReadAll(req.Body)
+		is.Equal(len(data), 0)
+
+		writer.WriteHeader(http.StatusNotModified)
+	}))
+
+	defer server.Close()
+
+	client := New(WithResponseErrors())
+
+	req := NewRequest(server.URL, http.MethodGet, &testReq{Message: "Hello, server!"},
+		WithIfNoneMatch[*testReq, *testRes](`"abc"`),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.StatusCode, http.StatusNotModified)
+	is.Equal(res.Res, nil)
+}
+
+func TestDo_Decompression(t *testing.T) {
+	is := is.New(t)
 
 	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
-		attempts++
+		writer.Header().Set("Content-Encoding", "gzip")
 
-		http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+		gzipWriter := gzip.NewWriter(writer)
+		_ = json.MarshalWrite(gzipWriter, &testRes{Reply: "Hello, client!"})
+		_ = gzipWriter.Close()
 	}))
 
 	defer server.Close()
 
 	client := New(
-		withInstantBackoff(),
-		WithMaxAttempts(5),
+		WithRequestMiddleware(func(req *http.Request) error {
+			req.Header.Set("Accept-Encoding", "gzip")
+			return nil
+		}),
 	)
 
-	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, &reqData)
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
 
-	_, err := Do(context.Background(), client, req)
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+}
 
-	_, ok := err.(*gobackoff.MaxAttemptsError) //nolint:errorlint // must be *gobackoff.MaxAttemptsError
-	is.True(ok)
+func TestWithAcceptEncoding(t *testing.T) {
+	is := is.New(t)
 
-	is.Equal(attempts, 5)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Accept-Encoding") != "gzip" {
+			_ = json.MarshalWrite(writer, &testRes{Reply: "plain"})
+			return
+		}
+
+		writer.Header().Set("Content-Encoding", "gzip")
+
+		gzipWriter := gzip.NewWriter(writer)
+		_ = json.MarshalWrite(gzipWriter, &testRes{Reply: "compressed"})
+		_ = gzipWriter.Close()
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithHTTPClient(&http.Client{
+			Transport: &http.Transport{
+				DisableCompression: true,
+			},
+		}),
+
+		WithDecompression(false),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil,
+		WithAcceptEncoding[*testReq, *testRes]("gzip"),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "compressed")
+
+	req = NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	res, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "plain")
 }
 
-func TestNewHTTPRequest_NoBody(t *testing.T) {
+func TestWithRequestDecompression(t *testing.T) {
 	is := is.New(t)
 
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Encoding", "gzip")
+
+		gzipWriter := gzip.NewWriter(writer)
+		_ = json.MarshalWrite(gzipWriter, &testRes{Reply: "Hello, client!"})
+		_ = gzipWriter.Close()
+	}))
+
+	defer server.Close()
+
 	client := New()
 
-	req := NewRequest("", http.MethodGet, nil,
-		WithMarshalRequestFunc[any, any](func(_ io.Writer, _ any) error {
-			is.Fail()
-			return nil
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil,
+		WithAcceptEncoding[*testReq, *testRes]("gzip"),
+		WithRequestDecompression[*testReq, *testRes](false),
+		WithUnmarshalResponseFunc[*testReq](func(httpRes *http.Response, val **testRes) error {
+			reader, err := gzip.NewReader(httpRes.Body)
+			is.NoErr(err)
+
+			return json.UnmarshalRead(reader, val)
 		}),
 	)
 
-	_, err := newHTTPRequest(context.Background(), client, req)
+	res, err := Do(context.Background(), client, req)
 	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
 }
 
-func TestResponse_IgnoreBody(t *testing.T) {
+func TestWithRequestContextValue(t *testing.T) {
 	is := is.New(t)
 
-	req := NewRequest("", http.MethodGet, nil,
-		WithIgnoreResponseBody[any, any](),
+	type tenantKey struct{}
+
+	type traceKey struct{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.Header.Get("X-Tenant"), "acme")
+		is.Equal(req.Header.Get("X-Trace"), "abc")
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithRequestMiddleware(func(req *http.Request) error {
+			tenant, _ := req.Context().Value(tenantKey{}).(string)
+			req.Header.Set("X-Tenant", tenant)
+
+			trace, _ := req.Context().Value(traceKey{}).(string)
+			req.Header.Set("X-Trace", trace)
 
-		WithUnmarshalResponseFunc[any](func(_ *http.Response, _ *any) error {
-			is.Fail()
 			return nil
 		}),
 	)
 
-	httpRes := http.Response{
-		StatusCode: http.StatusOK,
-		Status:     "OK",
-		Body:       http.NoBody,
-	}
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil,
+		WithRequestContextValue[*testReq, *testRes](tenantKey{}, "acme"),
+	)
 
-	_, err := response(&httpRes, req)
+	ctx := context.WithValue(context.Background(), traceKey{}, "abc")
+
+	_, err := Do(ctx, client, req)
 	is.NoErr(err)
 }
 
-func TestResponse_NoContent(t *testing.T) {
+func TestDo_Decompression_Disabled(t *testing.T) {
 	is := is.New(t)
 
-	req := NewRequest("", http.MethodGet, nil,
-		WithUnmarshalResponseFunc[any](func(_ *http.Response, _ *any) error {
-			is.Fail()
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Encoding", "gzip")
+
+		gzipWriter := gzip.NewWriter(writer)
+		_ = json.MarshalWrite(gzipWriter, &testRes{Reply: "Hello, client!"})
+		_ = gzipWriter.Close()
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithDecompression(false),
+
+		WithRequestMiddleware(func(req *http.Request) error {
+			req.Header.Set("Accept-Encoding", "gzip")
 			return nil
 		}),
 	)
 
-	httpRes := http.Response{
+	req := NewRequest(server.URL, http.MethodGet, (*testReq)(nil),
+		WithUnmarshalResponseFunc[*testReq](func(httpRes *http.Response, val **testRes) error {
+			reader, err := gzip.NewReader(httpRes.Body)
+			is.NoErr(err)
+
+			return json.UnmarshalRead(reader, val)
+		}),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+}
+
+func TestDo_RequestURL(t *testing.T) {
+	is := is.New(t)
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/old", func(writer http.ResponseWriter, req *http.Request) {
+		http.Redirect(writer, req, "/new?a=1", http.StatusFound)
+	})
+
+	mux.HandleFunc("/new", func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "No Content", http.StatusNoContent)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := New(WithBaseURI(server.URL))
+
+	req := NewRequest[*testReq, *testRes]("/old", http.MethodPost, nil)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.RequestURL, server.URL+"/new?a=1")
+	is.Equal(res.RequestMethod, http.MethodGet)
+}
+
+func TestDo_RedirectChain(t *testing.T) {
+	is := is.New(t)
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/a", func(writer http.ResponseWriter, req *http.Request) {
+		http.Redirect(writer, req, "/b", http.StatusFound)
+	})
+
+	mux.HandleFunc("/b", func(writer http.ResponseWriter, req *http.Request) {
+		http.Redirect(writer, req, "/c", http.StatusFound)
+	})
+
+	mux.HandleFunc("/c", func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "No Content", http.StatusNoContent)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := New(WithBaseURI(server.URL))
+
+	res, err := Do(context.Background(), client, NewRequest[*testReq, *testRes]("/a", http.MethodGet, nil))
+	is.NoErr(err)
+	is.Equal(len(res.RedirectChain), 2)
+	is.Equal(res.RedirectChain[0].String(), server.URL+"/b")
+	is.Equal(res.RedirectChain[1].String(), server.URL+"/c")
+
+	res, err = Do(context.Background(), client, NewRequest[*testReq, *testRes]("/c", http.MethodGet, nil))
+	is.NoErr(err)
+	is.Equal(len(res.RedirectChain), 0)
+}
+
+func TestDo_BeforeSend(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.Header.Get("X-Signature"), "signed:abc")
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithRequestMiddleware(func(req *http.Request) error {
+			req.Header.Set("X-Token", "abc")
+			return nil
+		}),
+	)
+
+	req := NewRequest(server.URL, http.MethodGet, (*testReq)(nil),
+		WithBeforeSend[*testReq, *testRes](func(req *http.Request) error {
+			req.Header.Set("X-Signature", "signed:"+req.Header.Get("X-Token"))
+			return nil
+		}),
+	)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+}
+
+func TestDo_RequestModifier(t *testing.T) {
+	is := is.New(t)
+
+	primary := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		is.Fail()
+	}))
+
+	defer primary.Close()
+
+	shadowAttempts := 0
+
+	shadow := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		shadowAttempts++
+
+		data, _ := io.ReadAll(req.Body)
+		is.Equal(string(data), `{"message":"Hello, server!"}`)
+
+		if shadowAttempts == 1 {
+			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer shadow.Close()
+
+	shadowURL, err := url.Parse(shadow.URL)
+	is.NoErr(err)
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest(primary.URL+"/foo", http.MethodPost, &testReq{Message: "Hello, server!"},
+		WithRequestModifier[*testReq, *testRes](func(req *http.Request) (*http.Request, error) {
+			req = req.Clone(req.Context())
+			req.URL.Host = shadowURL.Host
+			req.Host = ""
+
+			return req, nil
+		}),
+	)
+
+	_, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(shadowAttempts, 2)
+}
+
+func TestDo_TLS(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New(WithHTTPClient(server.Client()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.True(res.TLS != nil)
+	is.True(len(res.TLS.PeerCertificates) > 0)
+	is.True(res.TLS.PeerCertificates[0].Equal(server.Certificate()))
+}
+
+func TestWithBaseURI(t *testing.T) {
+	is := is.New(t)
+
+	reqData := testReq{
+		Message: "Hello, server!",
+	}
+
+	resData := testRes{
+		Reply: "Hello, client!",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.URL.Path, "/foo")
+
+		_ = json.MarshalWrite(writer, &resData)
+	}))
+
+	defer server.Close()
+
+	client := New(WithBaseURI(server.URL))
+
+	req := NewRequest[*testReq, *testRes]("/foo", http.MethodGet, &reqData)
+
+	_, _ = Do(context.Background(), client, req)
+}
+
+func TestIsRetryable(t *testing.T) {
+	errTest := errors.New("test") //nolint:goerr113 // dynamic error is okay here
+
+	tests := []struct {
+		name       string
+		err        error
+		statusCode int
+		want       bool
+	}{
+		{"error", errTest, 0, true},
+		{"canceled", fmt.Errorf("wrapped: %w", context.Canceled), 0, false},
+		{"deadline", context.DeadlineExceeded, 0, true},
+		{"bad request", nil, http.StatusBadRequest, false},
+		{"server error", nil, http.StatusInternalServerError, true},
+		{"decode", &DecodeError{Err: errTest}, http.StatusOK, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is.New(t)
+
+			var httpRes *http.Response
+			if test.statusCode != 0 {
+				httpRes = &http.Response{StatusCode: test.statusCode}
+			}
+
+			is.Equal(IsRetryable(test.err, httpRes), test.want)
+		})
+	}
+}
+
+func TestWithContentTypeOnlyWithBody(t *testing.T) {
+	is := is.New(t)
+
+	client := New(WithContentTypeOnlyWithBody())
+
+	req := NewRequest[*testReq, *testRes]("http://example.com", http.MethodGet, nil,
+		WithSendBody[*testReq, *testRes](false),
+	)
+
+	httpReq, err := newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+
+	_, ok := httpReq.Header["Content-Type"]
+	is.True(!ok)
+	is.Equal(httpReq.Header.Get("Accept"), "application/json")
+
+	req = NewRequest[*testReq, *testRes]("http://example.com", http.MethodPost, &testReq{Message: "Hi"})
+
+	httpReq, err = newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(httpReq.Header.Get("Content-Type"), "application/json; charset=UTF-8")
+
+	httpReq, err = newHTTPRequest(context.Background(), New(), NewRequest[*testReq, *testRes]("http://example.com",
+		http.MethodGet, nil, WithSendBody[*testReq, *testRes](false)))
+	is.NoErr(err)
+	is.Equal(httpReq.Header.Get("Content-Type"), "application/json; charset=UTF-8")
+}
+
+func TestResolveURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURI string
+		uri     string
+		want    string
+	}{
+		{"no base", "", "https://example.com/users", "https://example.com/users"},
+		{"leading slash", "https://example.com/api", "/users", "https://example.com/api/users"},
+		{"no slashes", "https://example.com/api", "users", "https://example.com/api/users"},
+		{"both slashes", "https://example.com/api/", "/users", "https://example.com/api/users"},
+		{"trailing slash", "https://example.com/api/", "users", "https://example.com/api/users"},
+		{"host only", "https://example.com", "/users", "https://example.com/users"},
+		{"query", "https://example.com/api", "users?a=1", "https://example.com/api/users?a=1"},
+		{"escaped", "https://example.com/api", "/users/a%2Fb", "https://example.com/api/users/a%2Fb"},
+		{"absolute", "https://example.com/api", "https://other.com/x", "https://other.com/x"},
+		{"empty", "https://example.com/api", "", "https://example.com/api"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is.New(t)
+
+			is.Equal(resolveURI(test.baseURI, test.uri), test.want)
+		})
+	}
+}
+
+func TestWithRequestBaseURI(t *testing.T) {
+	is := is.New(t)
+
+	resData := testRes{
+		Reply: "Hello, client!",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.URL.Path, "/shard/foo")
+
+		_ = json.MarshalWrite(writer, &resData)
+	}))
+
+	defer server.Close()
+
+	client := New(WithBaseURI("http://example.invalid"))
+
+	req := NewRequest[*testReq, *testRes]("/foo", http.MethodGet, nil,
+		WithRequestBaseURI[*testReq, *testRes](server.URL+"/shard"),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+
+	req = NewRequest[*testReq, *testRes](server.URL+"/shard/foo", http.MethodGet, nil,
+		WithRequestBaseURI[*testReq, *testRes]("http://example.invalid"),
+	)
+
+	res, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+}
+
+func TestDoInto(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		attempts++
+
+		switch {
+		case req.URL.Path == "/empty":
+			http.Error(writer, "No Content", http.StatusNoContent)
+
+		case attempts == 1:
+			http.Error(writer, "Service Unavailable", http.StatusServiceUnavailable)
+
+		default:
+			_, _ = writer.Write([]byte(`{"reply":"Hello, client!"}`))
+		}
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	var out testRes
+
+	req := NewRequest[*testReq, testRes](server.URL, http.MethodGet, nil)
+
+	res, err := DoInto(context.Background(), client, req, &out)
+	is.NoErr(err)
+	is.Equal(attempts, 2)
+	is.Equal(out.Reply, "Hello, client!")
+	is.Equal(res.Res.Reply, "Hello, client!")
+
+	req = NewRequest[*testReq, testRes](server.URL+"/empty", http.MethodGet, nil)
+
+	res, err = DoInto(context.Background(), client, req, &out)
+	is.NoErr(err)
+	is.Equal(res.StatusCode, http.StatusNoContent)
+	is.Equal(out.Reply, "Hello, client!")
+	is.Equal(res.Res.Reply, "")
+}
+
+func TestDo_DurationAttempts(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	clock := newFakeClock()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			http.Error(writer, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		clock.advance(10 * time.Millisecond)
+
+		_, _ = writer.Write([]byte(`{"reply":"Hello, client!"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(clock))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Attempts, 2)
+	is.Equal(res.Duration, 10*time.Millisecond)
+}
+
+func TestDo_Trailer(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Trailer", "Grpc-Status")
+
+		_, _ = writer.Write([]byte(`{"reply":"Hello, client!"}`))
+
+		writer.Header().Set("Grpc-Status", "0")
+		writer.Header().Set(http.TrailerPrefix+"Grpc-Message", "OK")
+	}))
+
+	defer server.Close()
+
+	client := New()
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+	is.Equal(res.Trailer.Get("Grpc-Status"), "0")
+	is.Equal(res.Trailer.Get("Grpc-Message"), "OK")
+}
+
+func TestWithRetryIdempotentOnly(t *testing.T) {
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+		http.Error(writer, "Service Unavailable", http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithMaxAttempts(3),
+		WithRetryIdempotentOnly(),
+	)
+
+	tests := []struct {
+		name   string
+		method string
+		opts   []RequestOpt[*testReq, *testRes]
+		want   int
+	}{
+		{"get", http.MethodGet, nil, 3},
+		{"put", http.MethodPut, nil, 3},
+		{"delete", http.MethodDelete, nil, 3},
+		{"post", http.MethodPost, nil, 1},
+		{"patch", http.MethodPatch, nil, 1},
+		{"post idempotent", http.MethodPost, []RequestOpt[*testReq, *testRes]{WithIdempotent[*testReq, *testRes]()}, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is.New(t)
+
+			attempts = 0
+
+			req := NewRequest(server.URL, test.method, nil, test.opts...)
+
+			_, err := Do(context.Background(), client, req)
+			is.True(err != nil)
+			is.Equal(attempts, test.want)
+		})
+	}
+}
+
+func TestDo_Retry(t *testing.T) {
+	is := is.New(t)
+
+	reqData := testReq{
+		Message: "Hello, server!",
+	}
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, &reqData)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(attempts, 2)
+}
+
+func TestDo_RetryFunc(t *testing.T) {
+	is := is.New(t)
+
+	reqData := testReq{
+		Message: "Hello, server!",
+	}
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+
+		WithRetry(func(_ context.Context, _ *http.Response, _ error) error {
+			return nil
+		}),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, &reqData)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(attempts, 2)
+}
+
+func TestDo_RetryFunc_Abort(t *testing.T) {
+	is := is.New(t)
+
+	reqData := testReq{
+		Message: "Hello, server!",
+	}
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	httpErr := errors.New("HTTP error") //nolint:goerr113 // dynamic error is okay here
+
+	client := New(
+		WithClock(newFakeClock()),
+
+		WithRetry(func(_ context.Context, httpRes *http.Response, _ error) error {
+			if httpRes.StatusCode < 200 || httpRes.StatusCode >= 300 {
+				return httpErr
+			}
+
+			return nil
+		}),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, &reqData)
+
+	_, err := Do(context.Background(), client, req)
+
+	abortErr, ok := err.(*gobackoff.AbortError) //nolint:errorlint // must be *gobackoff.AbortError
+	is.True(ok)
+	is.Equal(abortErr.Err, httpErr)
+}
+
+func TestDo_RetryFuncEx(t *testing.T) {
+	is := is.New(t)
+
+	attempts := map[string]int{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		attempts[req.URL.Path]++
+		http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+	}))
+
+	defer server.Close()
+
+	errNoRetry := errors.New("no retry")
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithMaxAttempts(3),
+
+		WithRetryEx(func(_ context.Context, httpReq *http.Request, _ *http.Response, _ error) error {
+			if httpReq.Method == http.MethodGet && httpReq.URL.Path == "/search" {
+				return nil
+			}
+
+			return errNoRetry
+		}),
+	)
+
+	_, err := Do(context.Background(), client, NewRequest[*testReq, *testRes](server.URL+"/search", http.MethodGet, nil))
+	is.True(err != nil)
+
+	_, err = Do(context.Background(), client, NewRequest[*testReq, *testRes](server.URL+"/charge", http.MethodPost, nil))
+	is.True(errors.Is(err, errNoRetry))
+
+	is.Equal(attempts["/search"], 3)
+	is.Equal(attempts["/charge"], 1)
+}
+
+func TestDo_RetryFuncV2(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	clock := newFakeClock()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		clock.advance(10 * time.Millisecond)
+		http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+	}))
+
+	defer server.Close()
+
+	errTooLong := errors.New("took too long")
+
+	var infos []RetryInfo
+
+	// the delays between attempts are at least 250ms, 375ms, and 562ms, so the limit is reached after 3 or 4 attempts
+	client := New(
+		WithClock(clock),
+		WithMaxAttempts(10),
+
+		WithRetryV2(func(_ context.Context, info RetryInfo) error {
+			infos = append(infos, info)
+
+			if info.Elapsed >= time.Second {
+				return errTooLong
+			}
+
+			return nil
+		}),
+	)
+
+	_, err := Do(context.Background(), client, NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil))
+	is.True(errors.Is(err, errTooLong))
+
+	is.True(attempts >= 3)
+	is.True(attempts <= 4)
+	is.Equal(len(infos), attempts)
+
+	for idx, info := range infos {
+		is.Equal(info.Attempt, idx+1)
+		is.Equal(info.Start, infos[0].Start)
+		is.Equal(info.Response.StatusCode, http.StatusInternalServerError)
+		is.Equal(info.Request.Method, http.MethodGet)
+
+		if idx > 0 {
+			is.True(info.Elapsed > infos[idx-1].Elapsed)
+		}
+	}
+}
+
+func TestDo_BodyFunc(t *testing.T) {
+	is := is.New(t)
+
+	var bodies []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		data, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(data))
+
+		if len(bodies) == 1 {
+			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	calls := 0
+
+	bodyFunc := func() (*testReq, error) {
+		calls++
+		return &testReq{Message: "nonce " + strconv.Itoa(calls)}, nil
+	}
+
+	req := NewRequest(server.URL, http.MethodPost, nil,
+		WithBodyFunc[*testReq, *testRes](bodyFunc),
+	)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(bodies, []string{`{"message":"nonce 1"}`, `{"message":"nonce 2"}`})
+
+	bodies = nil
+
+	req = NewRequest(server.URL, http.MethodPost, nil,
+		WithBodyFunc[*testReq, *testRes](bodyFunc),
+		WithMarshalOnce[*testReq, *testRes](),
+	)
+
+	_, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(bodies, []string{`{"message":"nonce 3"}`, `{"message":"nonce 3"}`})
+}
+
+func TestWithRequestBufferHint(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		data, _ := io.ReadAll(req.Body)
+		is.Equal(string(data), `{"message":"Hello, server!"}`)
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New()
+
+	for _, hint := range []int{0, 4, 1024} {
+		req := NewRequest(server.URL, http.MethodPost, &testReq{Message: "Hello, server!"},
+			WithRequestBufferHint[*testReq, *testRes](hint),
+		)
+
+		_, err := Do(context.Background(), client, req)
+		is.NoErr(err)
+	}
+}
+
+func BenchmarkRequestBody(b *testing.B) {
+	data := &testReq{
+		Message: strings.Repeat("x", 1024*1024),
+	}
+
+	client := New()
+
+	benchmarks := []struct {
+		name string
+		hint int
+	}{
+		{"no hint", 0},
+		{"hint", 1024*1024 + 64},
+	}
+
+	for _, benchmark := range benchmarks {
+		b.Run(benchmark.name, func(b *testing.B) {
+			req := NewRequest("http://example.invalid", http.MethodPost, data,
+				WithRequestBufferHint[*testReq, *testRes](benchmark.hint),
+			)
+
+			b.ReportAllocs()
+
+			for range b.N {
+				if _, err := requestBody(context.Background(), client, req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestDo_RetryOnEmptyBody(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			writer.WriteHeader(http.StatusOK)
+			return
+		}
+
+		_ = json.MarshalWrite(writer, &testRes{Reply: "Hello, client!"})
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+
+		WithRetry(func(_ context.Context, _ *http.Response, err error) error {
+			return err
+		}),
+	)
+
+	req := NewRequest(server.URL, http.MethodGet, (*testReq)(nil),
+		WithRetryOnEmptyBody[*testReq, *testRes](),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+
+	is.Equal(attempts, 2)
+}
+
+func TestDo_RespectRetryAfter(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			writer.Header().Set("Retry-After", "60")
+			http.Error(writer, "Service Unavailable", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	clock := newFakeClock()
+
+	client := New(
+		WithClock(clock),
+		WithRespectRetryAfter(20*time.Second),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(attempts, 2)
+	is.Equal(clock.sleeps, []time.Duration{20 * time.Second})
+}
+
+func TestDo_RetryMaxAttempts(t *testing.T) {
+	is := is.New(t)
+
+	reqData := testReq{
+		Message: "Hello, server!",
+	}
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithMaxAttempts(5),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, &reqData)
+
+	_, err := Do(context.Background(), client, req)
+
+	var maxAttemptsErr *gobackoff.MaxAttemptsError
+	is.True(errors.As(err, &maxAttemptsErr))
+
+	is.Equal(attempts, 5)
+}
+
+func TestDo_RetriesExhaustedError(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithMaxAttempts(3),
+		WithResponseErrors(),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+
+	exhaustedErr, ok := err.(*RetriesExhaustedError) //nolint:errorlint // must be *RetriesExhaustedError
+	is.True(ok)
+	is.Equal(exhaustedErr.Attempts, 3)
+	is.Equal(exhaustedErr.LastStatusCode, http.StatusInternalServerError)
+	is.Equal(exhaustedErr.LastStatus, "500 Internal Server Error")
+	is.Equal(string(exhaustedErr.LastBody), "Internal Server Error\n")
+
+	var resErr *ResponseError
+	is.True(errors.As(err, &resErr))
+}
+
+func TestDo_ResponseErrors(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "Not Found", http.StatusNotFound)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithMaxAttempts(1),
+		WithResponseErrors(),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+
+	var resErr *ResponseError
+	is.True(errors.As(err, &resErr))
+	is.Equal(resErr.StatusCode, http.StatusNotFound)
+	is.Equal(resErr.Status, "404 Not Found")
+	is.Equal(string(resErr.Body), "Not Found\n")
+}
+
+func TestDo_DecodeOnError(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.MarshalWrite(writer, &testRes{Reply: "invalid message"})
+	}))
+
+	defer server.Close()
+
+	client := New(WithMaxAttempts(1))
+
+	req := NewRequest(server.URL, http.MethodPost, &testReq{Message: "Hello, server!"},
+		WithDecodeOnError[*testReq, *testRes](),
+	)
+
+	res, err := Do(context.Background(), client, req)
+
+	var resErr *ResponseError
+	is.True(errors.As(err, &resErr))
+	is.Equal(resErr.StatusCode, http.StatusUnprocessableEntity)
+
+	is.Equal(res.StatusCode, http.StatusUnprocessableEntity)
+	is.Equal(res.Res.Reply, "invalid message")
+}
+
+func TestDo_UnwrapFinalError(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithMaxAttempts(2),
+		WithResponseErrors(),
+		WithUnwrapFinalError(),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+
+	var resErr *ResponseError
+	is.True(errors.As(err, &resErr))
+	is.Equal(resErr.StatusCode, http.StatusInternalServerError)
+	is.Equal(err.Error(), resErr.Error())
+
+	var exhaustedErr *RetriesExhaustedError
+	is.True(errors.As(err, &exhaustedErr))
+	is.Equal(exhaustedErr.Attempts, 2)
+
+	var maxAttemptsErr *gobackoff.MaxAttemptsError
+	is.True(errors.As(err, &maxAttemptsErr))
+}
+
+func TestDo_UnwrapFinalError_Abort(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "Bad Request", http.StatusBadRequest)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithResponseErrors(),
+		WithUnwrapFinalError(),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+	is.Equal(err.Error(), "400 Bad Request")
+
+	var abortErr *gobackoff.AbortError
+	is.True(errors.As(err, &abortErr))
+}
+
+func TestNewHTTPRequest_NoBody(t *testing.T) {
+	is := is.New(t)
+
+	client := New()
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithMarshalRequestFunc[any, any](func(_ io.Writer, _ any) error {
+			is.Fail()
+			return nil
+		}),
+	)
+
+	_, err := newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+}
+
+func TestNewHTTPRequest_BodylessMethods(t *testing.T) {
+	is := is.New(t)
+
+	client := New(WithBodylessMethods(http.MethodGet, http.MethodDelete))
+
+	req := NewRequest[*testReq, *testRes]("", http.MethodGet, &testReq{Message: "Hello, server!"})
+
+	httpReq, err := newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(httpReq.Body, http.NoBody)
+
+	req = NewRequest("", http.MethodGet, &testReq{Message: "Hello, server!"},
+		WithSendBody[*testReq, *testRes](true),
+	)
+
+	httpReq, err = newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+	is.True(httpReq.Body != http.NoBody)
+
+	req = NewRequest[*testReq, *testRes]("", http.MethodPost, &testReq{Message: "Hello, server!"})
+
+	httpReq, err = newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+	is.True(httpReq.Body != http.NoBody)
+}
+
+func TestNewHTTPRequest_EscapedPath(t *testing.T) {
+	is := is.New(t)
+
+	client := New(WithBaseURI("https://www.example.com"))
+
+	req := NewRequest("/users/", http.MethodGet, nil,
+		WithEscapedPath[any, any]("a/b c", "details"),
+	)
+
+	httpReq, err := newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(httpReq.URL.String(), "https://www.example.com/users/a%2Fb%20c/details")
+
+	req = NewRequest("/items?x=1#top", http.MethodGet, nil,
+		WithEscapedPath[any, any]("a b"),
+	)
+
+	httpReq, err = newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(httpReq.URL.String(), "https://www.example.com/items/a%20b?x=1#top")
+}
+
+func TestNewHTTPRequest_QueryParams(t *testing.T) {
+	is := is.New(t)
+
+	client := New()
+
+	req := NewRequest("/foo?a=1", http.MethodGet, nil,
+		WithQueryParams[any, any](url.Values{
+			"b": []string{"x y", "z&"},
+		}),
+
+		WithQueryParams[any, any](url.Values{
+			"a": []string{"2"},
+		}),
+	)
+
+	httpReq, err := newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(httpReq.URL.Query(), url.Values{
+		"a": []string{"1", "2"},
+		"b": []string{"x y", "z&"},
+	})
+}
+
+func TestNewHTTPRequest_ContentTypeAccept(t *testing.T) {
+	is := is.New(t)
+
+	client := New()
+
+	req := NewRequest[any, any]("", http.MethodGet, nil)
+
+	httpReq, err := newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(httpReq.Header.Get("Content-Type"), "application/json; charset=UTF-8")
+	is.Equal(httpReq.Header.Get("Accept"), "application/json")
+
+	client = New(
+		WithDefaultContentType("application/vnd.api+json"),
+		WithDefaultAccept("application/vnd.api+json"),
+	)
+
+	httpReq, err = newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(httpReq.Header.Get("Content-Type"), "application/vnd.api+json")
+	is.Equal(httpReq.Header.Get("Accept"), "application/vnd.api+json")
+
+	req = NewRequest("", http.MethodGet, nil,
+		WithContentType[any, any]("application/merge-patch+json"),
+		WithAccept[any, any]("application/problem+json"),
+	)
+
+	httpReq, err = newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(httpReq.Header.Get("Content-Type"), "application/merge-patch+json")
+	is.Equal(httpReq.Header.Get("Accept"), "application/problem+json")
+}
+
+func TestDo_InformationalCallback(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Link", "</style.css>; rel=preload; as=style")
+		writer.WriteHeader(http.StatusEarlyHints)
+
+		writer.Header().Del("Link")
+		_, _ = writer.Write([]byte(`{"reply":"Hello, client!"}`))
+	}))
+
+	defer server.Close()
+
+	var (
+		codes []int
+		links []string
+	)
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil,
+		WithInformationalCallback[*testReq, *testRes](func(code int, header http.Header) {
+			codes = append(codes, code)
+			links = append(links, header.Get("Link"))
+		}),
+		WithResponseHeaderCallback[*testReq, *testRes](func(_ http.Header, statusCode int) (bool, error) {
+			is.Equal(statusCode, http.StatusOK)
+			is.Equal(codes, []int{http.StatusEarlyHints})
+
+			return true, nil
+		}),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+
+	is.Equal(codes, []int{http.StatusEarlyHints})
+	is.Equal(links, []string{"</style.css>; rel=preload; as=style"})
+}
+
+func TestNewMethodRequests(t *testing.T) {
+	is := is.New(t)
+
+	reqData := &testReq{Message: "Hello, server!"}
+
+	is.Equal(NewGet[*testReq, *testRes]("/items").method, http.MethodGet)
+	is.Equal(NewGet[*testReq, *testRes]("/items").req, nil)
+	is.Equal(NewPost[*testReq, *testRes]("/items", reqData).method, http.MethodPost)
+	is.Equal(NewPost[*testReq, *testRes]("/items", reqData).req, reqData)
+	is.Equal(NewPut[*testReq, *testRes]("/items/1", reqData).method, http.MethodPut)
+	is.Equal(NewPatch[*testReq, *testRes]("/items/1", reqData).method, http.MethodPatch)
+	is.Equal(NewDelete[*testReq, *testRes]("/items/1").method, http.MethodDelete)
+
+	req := NewGet("/items", WithAccept[*testReq, *testRes]("text/plain"))
+	is.Equal(req.uri, "/items")
+	is.Equal(req.accept, "text/plain")
+}
+
+func TestNewGet_NewDelete_NoBody(t *testing.T) {
+	is := is.New(t)
+
+	type valueReq struct {
+		A int
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		data, _ := io.ReadAll(req.Body)
+		is.Equal(len(data), 0)
+		is.Equal(req.Header.Get("Content-Type"), "")
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New()
+
+	_, err := Do(context.Background(), client, NewGet[valueReq, *testRes](server.URL))
+	is.NoErr(err)
+
+	_, err = Do(context.Background(), client, NewDelete[valueReq, *testRes](server.URL))
+	is.NoErr(err)
+}
+
+func TestRequestPreset(t *testing.T) {
+	is := is.New(t)
+
+	preset := RequestPreset(
+		WithContentType[*testReq, *testRes]("application/vnd.api+json"),
+		WithAccept[*testReq, *testRes]("application/vnd.api+json"),
+		WithHeader[*testReq, *testRes]("X-Api-Version", "2"),
+	)
+
+	req := NewRequest("", http.MethodGet, (*testReq)(nil),
+		preset,
+		WithAccept[*testReq, *testRes]("application/json"),
+	)
+
+	httpReq, err := newHTTPRequest(context.Background(), New(), req)
+	is.NoErr(err)
+	is.Equal(httpReq.Header.Get("Content-Type"), "application/vnd.api+json")
+	is.Equal(httpReq.Header.Get("Accept"), "application/json")
+	is.Equal(httpReq.Header.Get("X-Api-Version"), "2")
+}
+
+func TestNewHTTPRequest_Header(t *testing.T) {
+	is := is.New(t)
+
+	client := New(
+		WithRequestMiddleware(func(req *http.Request) error {
+			is.Equal(req.Header.Values("X-Tenant"), []string{"a", "b"})
+
+			req.Header.Set("X-Tenant", "c")
+
+			return nil
+		}),
+	)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithHeader[any, any]("X-Tenant", "a"),
+		WithHeaders[any, any](http.Header{
+			"X-Tenant":        []string{"b"},
+			"Idempotency-Key": []string{"123"},
+			"Accept":          []string{"text/plain"},
+		}),
+	)
+
+	httpReq, err := newHTTPRequest(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(httpReq.Header.Get("X-Tenant"), "c")
+	is.Equal(httpReq.Header.Get("Idempotency-Key"), "123")
+	is.Equal(httpReq.Header.Get("Accept"), "text/plain")
+}
+
+func TestDo_Header_ConcurrentMiddleware(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.Header.Values("X-Tenant"), []string{"a", "b", "c", "d"})
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithRequestMiddleware(func(req *http.Request) error {
+			req.Header.Add("X-Tenant", "d")
+			return nil
+		}),
+	)
+
+	// three values, so that the slice has spare capacity that Add could append into
+	req := NewRequest(server.URL, http.MethodGet, nil,
+		WithHeader[any, any]("X-Tenant", "a"),
+		WithHeader[any, any]("X-Tenant", "b"),
+		WithHeader[any, any]("X-Tenant", "c"),
+	)
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := Do(context.Background(), client, req)
+			is.NoErr(err)
+		}()
+	}
+
+	wg.Wait()
+
+	is.Equal(req.header.Values("X-Tenant"), []string{"a", "b", "c"})
+}
+
+func TestNewHTTPRequest_OptionalHeader(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithOptionalHeader[any, any]("X-Tenant", "a"),
+		WithOptionalHeader[any, any]("X-Request-Id", ""),
+	)
+
+	httpReq, err := newHTTPRequest(context.Background(), New(), req)
+	is.NoErr(err)
+	is.Equal(httpReq.Header.Values("X-Tenant"), []string{"a"})
+
+	_, ok := httpReq.Header["X-Request-Id"]
+	is.True(!ok)
+}
+
+func TestNewHTTPRequest_PropagateDeadline(t *testing.T) {
+	is := is.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := New()
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithPropagateDeadline[any, any]("X-Request-Deadline"),
+	)
+
+	httpReq, err := newHTTPRequest(ctx, client, req)
+	is.NoErr(err)
+
+	first, err := strconv.Atoi(httpReq.Header.Get("X-Request-Deadline"))
+	is.NoErr(err)
+	is.True(first > 9000 && first <= 10000)
+
+	time.Sleep(50 * time.Millisecond)
+
+	httpReq, err = newHTTPRequest(ctx, client, req)
+	is.NoErr(err)
+
+	second, err := strconv.Atoi(httpReq.Header.Get("X-Request-Deadline"))
+	is.NoErr(err)
+	is.True(second < first)
+}
+
+func TestNewHTTPRequest_PropagateDeadline_GRPC(t *testing.T) {
+	is := is.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := New()
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithPropagateDeadline[any, any]("grpc-timeout"),
+	)
+
+	httpReq, err := newHTTPRequest(ctx, client, req)
+	is.NoErr(err)
+	is.True(strings.HasSuffix(httpReq.Header.Get("grpc-timeout"), "m"))
+}
+
+func TestResponse_IgnoreBody(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithIgnoreResponseBody[any, any](),
+
+		WithUnmarshalResponseFunc[any](func(_ *http.Response, _ *any) error {
+			is.Fail()
+			return nil
+		}),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "OK",
+		Body:       http.NoBody,
+	}
+
+	_, err := response(New(), &httpRes, req)
+	is.NoErr(err)
+}
+
+func TestResponse_NoContent(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithUnmarshalResponseFunc[any](func(_ *http.Response, _ *any) error {
+			is.Fail()
+			return nil
+		}),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusNoContent,
+		Status:     "No Content",
+		Body:       http.NoBody,
+	}
+
+	_, err := response(New(), &httpRes, req)
+	is.NoErr(err)
+}
+
+func TestResponse_CaptureRawBody(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithCaptureRawBody[any, *testRes](1024),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "OK",
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{"reply":"Hello, client!"}`))),
+	}
+
+	res, err := response(New(), &httpRes, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+	is.Equal(string(res.RawBody), `{"reply":"Hello, client!"}`)
+}
+
+type rot13Reader struct {
+	reader io.Reader
+	closed bool
+}
+
+func (r *rot13Reader) Read(buf []byte) (int, error) {
+	n, err := r.reader.Read(buf)
+
+	for i, b := range buf[:n] {
+		switch {
+		case b >= 'a' && b <= 'z':
+			buf[i] = 'a' + (b-'a'+13)%26
+
+		case b >= 'A' && b <= 'Z':
+			buf[i] = 'A' + (b-'A'+13)%26
+		}
+	}
+
+	return n, err
+}
+
+func (r *rot13Reader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestResponse_ReaderWrapper(t *testing.T) {
+	is := is.New(t)
+
+	var wrapper *rot13Reader
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithCaptureRawBody[any, *testRes](1024),
+
+		WithResponseReaderWrapper[any, *testRes](func(reader io.Reader) io.Reader {
+			wrapper = &rot13Reader{reader: reader}
+			return wrapper
+		}),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "OK",
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{"ercyl":"Uryyb, pyvrag!"}`))),
+	}
+
+	res, err := response(New(), &httpRes, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+	is.Equal(string(res.RawBody), `{"ercyl":"Uryyb, pyvrag!"}`)
+	is.True(wrapper.closed)
+}
+
+func TestResponse_CaptureRawBody_SizeHint(t *testing.T) {
+	is := is.New(t)
+
+	body := []byte(`{"reply":"` + strings.Repeat("x", 64*1024) + `"}`)
+
+	for _, hint := range []int64{0, 10, int64(len(body)), 1024 * 1024} {
+		req := NewRequest("", http.MethodGet, nil,
+			WithCaptureRawBody[any, *testRes](1024*1024),
+			WithResponseSizeHint[any, *testRes](hint),
+		)
+
+		httpRes := http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "OK",
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}
+
+		res, err := response(New(), &httpRes, req)
+		is.NoErr(err)
+		is.Equal(res.RawBody, body)
+		is.Equal(len(res.Res.Reply), 64*1024)
+	}
+
+	captureAllocs := func(hint int64) float64 {
+		return testing.AllocsPerRun(10, func() {
+			httpRes := http.Response{
+				Body: io.NopCloser(bytes.NewReader(body)),
+			}
+
+			_, _, _ = captureRawBody(&httpRes, 1024*1024, hint)
+		})
+	}
+
+	is.True(captureAllocs(int64(len(body))) < captureAllocs(0))
+}
+
+func TestResponse_CaptureRawBody_DecodeError(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithCaptureRawBody[any, *testRes](5),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "OK",
+		Body:       io.NopCloser(bytes.NewReader([]byte("Internal Server Error"))),
+	}
+
+	_, err := response(New(), &httpRes, req)
+
+	var decodeErr *DecodeError
+	is.True(errors.As(err, &decodeErr))
+	is.Equal(string(decodeErr.RawBody), "Inter")
+}
+
+func TestDo_DecodeErrorBody(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		writer.WriteHeader(http.StatusBadGateway)
+		_, _ = writer.Write([]byte("<html><body>Bad Gateway</body></html>"))
+	}))
+
+	defer server.Close()
+
+	client := New(WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+
+	var decodeErr *DecodeError
+	is.True(errors.As(err, &decodeErr))
+	is.Equal(decodeErr.StatusCode, http.StatusBadGateway)
+	is.Equal(string(decodeErr.RawBody), "<html><body>Bad Gateway</body></html>")
+	is.True(strings.Contains(err.Error(), `(body: "<html><body>Bad Gateway</body></html>")`))
+
+	client = New(WithMaxAttempts(1), WithDecodeErrorBodySize(6))
+
+	_, err = Do(context.Background(), client, req)
+	is.True(errors.As(err, &decodeErr))
+	is.Equal(string(decodeErr.RawBody), "<html>")
+
+	client = New(WithMaxAttempts(1), WithDecodeErrorBodySize(0))
+
+	_, err = Do(context.Background(), client, req)
+	is.True(errors.As(err, &decodeErr))
+	is.Equal(decodeErr.RawBody, nil)
+}
+
+func TestResponse_AllowEmptyBody(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithRetryOnEmptyBody[any, *testRes](),
+		WithAllowEmptyBody[any, *testRes](),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "OK",
+		Body:       http.NoBody,
+	}
+
+	res, err := response(New(), &httpRes, req)
+	is.NoErr(err)
+	is.Equal(res.Res, nil)
+}
+
+func TestResponse_ErrorOnNullResult(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithErrorOnNullResult[any, *testRes](),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "OK",
+		Body:       io.NopCloser(bytes.NewReader([]byte("null"))),
+	}
+
+	_, err := response(New(), &httpRes, req)
+
+	var nullErr *NullResultError
+	is.True(errors.As(err, &nullErr))
+	is.Equal(nullErr.StatusCode, http.StatusOK)
+
+	httpRes.Body = io.NopCloser(bytes.NewReader([]byte(`{"reply":"Hello, client!"}`)))
+
+	res, err := response(New(), &httpRes, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+}
+
+func TestResponse_RejectUnknownFields(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithRejectUnknownFields[any, *testRes](),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "OK",
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{"reply":"Hello, client!","extra":true}`))),
+	}
+
+	_, err := response(New(), &httpRes, req)
+
+	var decodeErr *DecodeError
+	is.True(errors.As(err, &decodeErr))
+	is.True(strings.Contains(err.Error(), `"extra"`))
+
+	httpRes.Body = io.NopCloser(bytes.NewReader([]byte(`{"reply":"Hello, client!"}`)))
+
+	res, err := response(New(), &httpRes, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+}
+
+func TestResponse_HeaderCallback(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithResponseHeaderCallback[any, any](func(header http.Header, statusCode int) (bool, error) {
+			is.Equal(statusCode, http.StatusOK)
+
+			size, _ := strconv.Atoi(header.Get("Content-Length"))
+
+			return size <= 1024, nil
+		}),
+
+		WithUnmarshalResponseFunc[any](func(_ *http.Response, _ *any) error {
+			is.Fail()
+			return nil
+		}),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "OK",
+		Header:     http.Header{"Content-Length": []string{"1048576"}},
+		Body:       http.NoBody,
+	}
+
+	res, err := response(New(), &httpRes, req)
+	is.NoErr(err)
+	is.Equal(res.StatusCode, http.StatusOK)
+}
+
+func TestResponse_Header(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest[any, any]("", http.MethodGet, nil)
+
+	httpRes := http.Response{
 		StatusCode: http.StatusNoContent,
 		Status:     "No Content",
+		Header:     http.Header{"X-Request-Id": []string{"123"}},
 		Body:       http.NoBody,
 	}
 
-	_, err := response(&httpRes, req)
+	res, err := response(New(), &httpRes, req)
 	is.NoErr(err)
+	is.Equal(res.Header.Get("X-Request-Id"), "123")
 }
 
-func withInstantBackoff() ClientOpt {
-	return WithBackoff(gobackoff.New(
-		gobackoff.WithInitialDelay(1*time.Nanosecond),
-		gobackoff.WithMultiplier(1.0),
-		gobackoff.WithJitter(0.0),
-	))
+func TestPropagateTraceContext(t *testing.T) {
+	is := is.New(t)
+
+	member, _ := baggage.NewMember("user", "alice")
+	bag, _ := baggage.New(member)
+
+	ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+	client := New(WithRequestMiddleware(PropagateTraceContext()))
+
+	req := NewRequest[any, any]("", http.MethodGet, nil)
+
+	httpReq, err := newHTTPRequest(ctx, client, req)
+	is.NoErr(err)
+	is.Equal(httpReq.Header.Get("baggage"), "user=alice")
 }
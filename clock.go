@@ -0,0 +1,108 @@
+package gojsonclient
+
+import (
+	"context"
+	"time"
+)
+
+// Clock provides the current time and a way to wait for a duration. It is used by a Client wherever it measures
+// time or waits, so that tests can control time deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer creates a Timer that sends the current time on its channel after the duration has elapsed.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event created by a Clock.
+type Timer interface {
+	// C returns the channel on which the current time is sent when the timer fires.
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing. It returns false if the timer has already fired or been stopped.
+	Stop() bool
+}
+
+const (
+	// clockBackoffInitialDelay is the delay before the first new attempt if a clock has been configured.
+	clockBackoffInitialDelay = 500 * time.Millisecond
+
+	// clockBackoffMaxDelay is the maximum delay between attempts if a clock has been configured.
+	clockBackoffMaxDelay = 10 * time.Second
+
+	// clockBackoffMultiplier is the factor by which the delay increases for each attempt if a clock has been
+	// configured.
+	clockBackoffMultiplier = 1.5
+)
+
+// systemClock is a Clock that uses the system time.
+type systemClock struct{}
+
+// systemTimer is a Timer that uses the system time.
+type systemTimer struct {
+	timer *time.Timer
+}
+
+var (
+	_ Clock = systemClock{}
+	_ Timer = systemTimer{}
+)
+
+// WithClock configures a Client to use clock to measure time and to wait, for example before retrying
+// a request after the delay requested by the server (see WithRespectRetryAfter and WithRateLimitError),
+// or before reconnecting to a Server-Sent Events stream. The default is to use the system time.
+//
+// Since the backoff configured using WithBackoff always uses the system time, it is replaced: the delays between
+// attempts are computed by the Client instead, in the same way as gobackoff.New() does (starting at 500ms,
+// multiplied by 1.5 for each attempt, at most 10s, with a jitter of ±50%), and waited for using clock.
+// If the Client has been configured using WithDecorrelatedJitter, those delays are used instead.
+func WithClock(clock Clock) ClientOpt {
+	if clock == nil {
+		panic("clock must not be nil")
+	}
+
+	return func(client *Client) {
+		client.clock = clock
+	}
+}
+
+// Now implements Clock.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer implements Clock.
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{
+		timer: time.NewTimer(d),
+	}
+}
+
+// C implements Timer.
+func (t systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Stop implements Timer.
+func (t systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// sleep waits for delay to elapse according to clock, or until ctx is canceled.
+func sleep(ctx context.Context, clock Clock, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := clock.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
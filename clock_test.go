@@ -0,0 +1,157 @@
+package gojsonclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// fakeClock is a Clock whose time only advances when waiting. Waiting returns immediately.
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+// fakeTimer is a Timer that has already fired.
+type fakeTimer struct {
+	ch chan time.Time
+}
+
+var (
+	_ Clock = (*fakeClock)(nil)
+	_ Timer = (*fakeTimer)(nil)
+)
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// advance moves the clock forward by d, for example to simulate a slow server.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+	c.sleeps = append(c.sleeps, d)
+
+	timer := fakeTimer{
+		ch: make(chan time.Time, 1),
+	}
+
+	timer.ch <- c.now
+
+	return &timer
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	return false
+}
+
+func TestWithClock(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts < 3 {
+			writer.Header().Set("Retry-After", "30")
+			http.Error(writer, "Service Unavailable", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	clock := newFakeClock()
+	start := clock.Now()
+
+	var elapsed []time.Duration
+
+	client := New(
+		WithClock(clock),
+		WithRespectRetryAfter(time.Minute),
+
+		WithRetryV2(func(_ context.Context, info RetryInfo) error {
+			is.Equal(info.Start, start)
+			elapsed = append(elapsed, info.Elapsed)
+
+			return nil
+		}),
+	)
+
+	_, err := Do(context.Background(), client, NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil))
+	is.NoErr(err)
+
+	is.Equal(attempts, 3)
+	is.Equal(clock.sleeps, []time.Duration{30 * time.Second, 30 * time.Second})
+	is.Equal(elapsed, []time.Duration{0, 30 * time.Second, 60 * time.Second})
+}
+
+func TestWithClock_Backoff(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts < 4 {
+			http.Error(writer, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	clock := newFakeClock()
+
+	client := New(WithClock(clock))
+
+	_, err := Do(context.Background(), client, NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil))
+	is.NoErr(err)
+
+	is.Equal(attempts, 4)
+	is.Equal(len(clock.sleeps), 3)
+
+	base := clockBackoffInitialDelay
+
+	for _, delay := range clock.sleeps {
+		is.True(delay >= base/2)
+		is.True(delay < base*3/2)
+
+		base = time.Duration(float64(base) * clockBackoffMultiplier)
+	}
+}
@@ -0,0 +1,127 @@
+package gojsonclient
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// coalescer tracks in-flight requests that carry an idempotency key, so that concurrent requests with the same key
+// can share a single result.
+type coalescer struct {
+	mutex sync.Mutex
+	calls map[coalesceKey]*coalescedCall
+}
+
+// coalesceKey identifies in-flight requests. The response data type is part of the key so that requests
+// with the same idempotency key but different response data types do not share results.
+type coalesceKey struct {
+	key     string
+	resType reflect.Type
+}
+
+// coalescedCall is an in-flight request whose result is shared by all callers.
+type coalescedCall struct {
+	done    chan struct{}
+	waiters int
+	res     any
+	info    *FinalizeInfo
+	err     error
+}
+
+// WithIdempotencyKey configures a Request to send key in the Idempotency-Key header, allowing the server to
+// recognize repeated requests. Since repeating the request is then safe, it is also retried if the Client has
+// been configured using WithRetryIdempotentOnly (see WithIdempotent).
+func WithIdempotencyKey[Req any, Res any](key string) RequestOpt[Req, Res] {
+	if key == "" {
+		panic("key must not be empty")
+	}
+
+	return func(req *Request[Req, Res]) {
+		req.idempotencyKey = key
+		req.idempotent = true
+	}
+}
+
+// WithRequestCoalescing configures a Client to coalesce concurrent calls to Do for requests that carry the same
+// idempotency key (see WithIdempotencyKey). Only the first call executes the request, while the others wait for it
+// to complete and then return the same *Response and error. The shared Response must not be modified by callers.
+//
+// If the context of a waiting call is canceled, that call returns the context's error, while the request continues.
+// If the context of the executing call is canceled, all calls sharing its result fail.
+// Requests are only coalesced while they are in flight; results are not cached.
+func WithRequestCoalescing() ClientOpt {
+	return func(client *Client) {
+		client.coalescer = &coalescer{
+			calls: map[coalesceKey]*coalescedCall{},
+		}
+	}
+}
+
+// coalesce calls fun, unless a call for a request with the same idempotency key as req and the same response data
+// type is already in flight, in which case it waits for that call to complete and returns its result. The finalize
+// information returned is a copy for each call, so that finalizers run for every call.
+func coalesce[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res],
+	fun func() (*Response[Res], *FinalizeInfo, error),
+) (*Response[Res], *FinalizeInfo, error) {
+	coal := client.coalescer
+
+	mapKey := coalesceKey{
+		key:     req.idempotencyKey,
+		resType: reflect.TypeFor[Res](),
+	}
+
+	coal.mutex.Lock()
+
+	if call, ok := coal.calls[mapKey]; ok {
+		call.waiters++
+		coal.mutex.Unlock()
+
+		start := client.clock.Now()
+
+		select {
+		case <-call.done:
+			res, _ := call.res.(*Response[Res])
+
+			var info *FinalizeInfo
+
+			if call.info != nil {
+				waiterInfo := *call.info
+				waiterInfo.Duration = client.clock.Now().Sub(start)
+				info = &waiterInfo
+			}
+
+			return res, info, call.err
+
+		case <-ctx.Done():
+			err := ctx.Err()
+			return nil, newFinalizeInfo(client, req, nil, 0, client.clock.Now().Sub(start), err), err
+		}
+	}
+
+	call := &coalescedCall{
+		done: make(chan struct{}),
+	}
+
+	coal.calls[mapKey] = call
+
+	coal.mutex.Unlock()
+
+	defer func() {
+		coal.mutex.Lock()
+		delete(coal.calls, mapKey)
+		coal.mutex.Unlock()
+
+		close(call.done)
+	}()
+
+	res, info, err := fun()
+	call.res, call.err = res, err
+
+	if info != nil {
+		sharedInfo := *info
+		call.info = &sharedInfo
+	}
+
+	return res, info, err
+}
@@ -0,0 +1,172 @@
+package gojsonclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestWithRequestCoalescing(t *testing.T) {
+	is := is.New(t)
+
+	var requests atomic.Int32
+
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+
+		is.Equal(req.Header.Get("Idempotency-Key"), "abc")
+
+		<-release
+
+		_, _ = writer.Write([]byte(`{"reply":"hello"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithRequestCoalescing())
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: "hi"},
+		WithIdempotencyKey[*testReq, *testRes]("abc"),
+	)
+
+	var (
+		wg   sync.WaitGroup
+		ress [2]*Response[*testRes]
+		errs [2]error
+	)
+
+	for i := range 2 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ress[i], errs[i] = Do(context.Background(), client, req)
+		}()
+	}
+
+	waitForWaiters(client.coalescer, 1)
+	close(release)
+
+	wg.Wait()
+
+	is.NoErr(errs[0])
+	is.NoErr(errs[1])
+	is.Equal(requests.Load(), int32(1))
+	is.True(ress[0] == ress[1])
+	is.Equal(ress[0].Res.Reply, "hello")
+}
+
+func TestWithRequestCoalescing_Finalizer(t *testing.T) {
+	is := is.New(t)
+
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		<-release
+
+		_, _ = writer.Write([]byte(`{"reply":"hello"}`))
+	}))
+
+	defer server.Close()
+
+	var (
+		mutex sync.Mutex
+		infos []FinalizeInfo
+	)
+
+	client := New(
+		WithRequestCoalescing(),
+
+		WithFinalizer(func(_ context.Context, info *FinalizeInfo) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			infos = append(infos, *info)
+		}),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: "hi"},
+		WithIdempotencyKey[*testReq, *testRes]("abc"),
+	)
+
+	var wg sync.WaitGroup
+
+	for range 2 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := Do(context.Background(), client, req)
+			is.NoErr(err)
+		}()
+	}
+
+	waitForWaiters(client.coalescer, 1)
+	close(release)
+
+	wg.Wait()
+
+	is.Equal(len(infos), 2)
+
+	for _, info := range infos {
+		is.Equal(info.StatusCode, http.StatusOK)
+		is.Equal(info.Attempts, 1)
+		is.NoErr(info.Err)
+	}
+}
+
+func TestWithRequestCoalescing_DifferentKeys(t *testing.T) {
+	is := is.New(t)
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+
+		_, _ = writer.Write([]byte(`{"reply":"hello"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithRequestCoalescing())
+
+	for _, key := range []string{"a", "b"} {
+		req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: "hi"},
+			WithIdempotencyKey[*testReq, *testRes](key),
+		)
+
+		_, err := Do(context.Background(), client, req)
+		is.NoErr(err)
+	}
+
+	is.Equal(requests.Load(), int32(2))
+	is.Equal(len(client.coalescer.calls), 0)
+}
+
+// waitForWaiters waits until the in-flight call of coal has the given number of waiters.
+func waitForWaiters(coal *coalescer, waiters int) {
+	for {
+		coal.mutex.Lock()
+
+		for _, call := range coal.calls {
+			if call.waiters == waiters {
+				coal.mutex.Unlock()
+				return
+			}
+		}
+
+		coal.mutex.Unlock()
+
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,65 @@
+package gojsonclient
+
+import (
+	"errors"
+	"io"
+)
+
+// ConnectionClosedError is returned when the connection is closed by the server before the response body
+// has been read completely, for example because the server crashed while sending it. By default, requests
+// that fail with this error are retried.
+type ConnectionClosedError struct {
+	// Err is the error returned while reading the response body.
+	Err error
+}
+
+// trackedBody is an io.ReadCloser that records the number of bytes read from the underlying body, and whether
+// reading it ended unexpectedly.
+type trackedBody struct {
+	body          io.ReadCloser
+	read          int64
+	unexpectedEOF bool
+}
+
+var (
+	_ error         = (*ConnectionClosedError)(nil)
+	_ io.ReadCloser = (*trackedBody)(nil)
+)
+
+// Read implements io.Reader.
+func (b *trackedBody) Read(buf []byte) (int, error) {
+	n, err := b.body.Read(buf)
+	b.read += int64(n)
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		b.unexpectedEOF = true
+	}
+
+	return n, err //nolint:wrapcheck // we don't add new info here
+}
+
+// Close implements io.Closer.
+func (b *trackedBody) Close() error {
+	return b.body.Close() //nolint:wrapcheck // we don't add new info here
+}
+
+// connectionClosed returns a *ConnectionClosedError if reading body ended unexpectedly, otherwise it returns err.
+func connectionClosed(body *trackedBody, err error) error {
+	if err == nil || !body.unexpectedEOF {
+		return err
+	}
+
+	return &ConnectionClosedError{
+		Err: io.ErrUnexpectedEOF,
+	}
+}
+
+// Error implements error.
+func (e *ConnectionClosedError) Error() string {
+	return "connection closed while reading response body: " + e.Err.Error()
+}
+
+// Unwrap returns e.Err.
+func (e *ConnectionClosedError) Unwrap() error {
+	return e.Err
+}
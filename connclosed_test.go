@@ -0,0 +1,92 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDo_ConnectionClosed(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		writer.Header().Set("Content-Type", "application/json")
+
+		if attempts == 1 {
+			writer.Header().Set("Content-Length", "100")
+			_, _ = writer.Write([]byte(`{"reply":"hel`))
+
+			return
+		}
+
+		_, _ = writer.Write([]byte(`{"reply":"hello"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(attempts, 2)
+	is.Equal(res.Res.Reply, "hello")
+}
+
+func TestDo_ConnectionClosed_Error(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Header().Set("Content-Length", "100")
+		_, _ = writer.Write([]byte(`{"reply":"hel`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()), WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+
+	var closedErr *ConnectionClosedError
+	is.True(errors.As(err, &closedErr))
+	is.True(errors.Is(err, io.ErrUnexpectedEOF))
+
+	var decodeErr *DecodeError
+	is.True(!errors.As(err, &decodeErr))
+}
+
+func TestDo_TruncatedJSON(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{"reply":"hel`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()), WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+
+	var decodeErr *DecodeError
+	is.True(errors.As(err, &decodeErr))
+
+	var closedErr *ConnectionClosedError
+	is.True(!errors.As(err, &closedErr))
+}
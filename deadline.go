@@ -0,0 +1,61 @@
+package gojsonclient
+
+import (
+	"context"
+	"time"
+)
+
+// RetryDeadlineError is returned when a request is not retried because the delay before the next attempt
+// would exceed the deadline of the context. It matches context.DeadlineExceeded when using errors.Is.
+type RetryDeadlineError struct {
+	// Delay is the delay before the next attempt.
+	Delay time.Duration
+
+	// Remaining is the time that was remaining until the deadline of the context.
+	Remaining time.Duration
+
+	// Err is the error returned by the last attempt.
+	Err error
+}
+
+var _ error = (*RetryDeadlineError)(nil)
+
+// retrySleep waits for delay to elapse before the next attempt, after the previous attempt failed with err.
+// If ctx has a deadline that would be reached before delay elapses, it returns a *RetryDeadlineError immediately.
+func retrySleep(ctx context.Context, clock Clock, delay time.Duration, err error) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); delay >= remaining {
+			return &RetryDeadlineError{
+				Delay:     delay,
+				Remaining: remaining,
+				Err:       err,
+			}
+		}
+	}
+
+	return sleep(ctx, clock, delay)
+}
+
+// Error implements error.
+func (e *RetryDeadlineError) Error() string {
+	msg := "retry delay of " + e.Delay.String() + " exceeds context deadline"
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+// Unwrap returns e.Err.
+func (e *RetryDeadlineError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is context.DeadlineExceeded.
+func (e *RetryDeadlineError) Is(target error) bool {
+	return target == context.DeadlineExceeded //nolint:errorlint // must be the exact sentinel
+}
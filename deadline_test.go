@@ -0,0 +1,93 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDo_RetryDeadline(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		writer.Header().Set("Retry-After", "60")
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	clock := newFakeClock()
+
+	client := New(
+		WithClock(clock),
+		WithRespectRetryAfter(time.Minute),
+		WithResponseErrors(),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := Do(ctx, client, req)
+
+	var deadlineErr *RetryDeadlineError
+	is.True(errors.As(err, &deadlineErr))
+	is.Equal(deadlineErr.Delay, time.Minute)
+	is.True(errors.Is(err, context.DeadlineExceeded))
+
+	var resErr *ResponseError
+	is.True(errors.As(err, &resErr))
+	is.Equal(resErr.StatusCode, http.StatusServiceUnavailable)
+
+	is.Equal(attempts, 1)
+	is.Equal(len(clock.sleeps), 0)
+}
+
+func TestDo_RetryDeadline_WithinDeadline(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			writer.Header().Set("Retry-After", "1")
+			writer.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		_, _ = writer.Write([]byte(`{"reply":"hello"}`))
+	}))
+
+	defer server.Close()
+
+	clock := newFakeClock()
+
+	client := New(
+		WithClock(clock),
+		WithRespectRetryAfter(time.Minute),
+		WithResponseErrors(),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	res, err := Do(ctx, client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "hello")
+	is.Equal(clock.sleeps, []time.Duration{time.Second})
+}
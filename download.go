@@ -0,0 +1,184 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// rangeResult describes the part of a resource that has been received by a single ranged request.
+type rangeResult struct {
+	// n is the number of bytes received.
+	n int64
+
+	// total is the total size of the resource, or -1 if unknown.
+	total int64
+
+	// complete is true if the full resource has been received.
+	complete bool
+}
+
+var (
+	errInvalidContentRange = errors.New("invalid Content-Range header")
+	errUnexpectedStatus    = errors.New("unexpected HTTP status")
+)
+
+// DownloadRanged downloads the resource at uri using a series of http.MethodGet requests with a Range header,
+// each requesting at most chunkSize bytes, and writes the bytes to writer at their respective offsets.
+// Each chunk is retried individually according to the client's retry function and maximum number of attempts.
+// It returns the total number of bytes downloaded. If the server returns fewer bytes than requested but reports
+// the total size of the resource, the download continues until the total size has been received.
+//
+// If the server does not support range requests (that is, it responds with http.StatusOK instead of
+// http.StatusPartialContent), the full resource is downloaded in one request.
+func DownloadRanged(ctx context.Context, client *Client, uri string, writer io.WriterAt, chunkSize int64) (int64, error) {
+	if chunkSize < 1 {
+		panic("chunkSize must be >=1")
+	}
+
+	var written int64
+
+	for {
+		res, err := Do(ctx, client, newRangeRequest(uri, writer, written, chunkSize))
+		if err != nil {
+			return written, fmt.Errorf("download range %d-%d: %w", written, written+chunkSize-1, err)
+		}
+
+		if res.Res.complete {
+			return res.Res.n, nil
+		}
+
+		written += res.Res.n
+
+		// servers may return fewer bytes than requested, so a short chunk only marks the end if the total is unknown
+		if res.Res.total < 0 {
+			if res.Res.n < chunkSize {
+				return written, nil
+			}
+
+			continue
+		}
+
+		if written >= res.Res.total {
+			return written, nil
+		}
+
+		if res.Res.n == 0 {
+			return written, fmt.Errorf("download range %d-%d: %w", written, written+chunkSize-1, io.ErrUnexpectedEOF)
+		}
+	}
+}
+
+func newRangeRequest(uri string, writer io.WriterAt, start int64, chunkSize int64) *Request[any, rangeResult] {
+	req := NewRequest(uri, http.MethodGet, nil,
+		WithAccept[any, rangeResult]("*/*"),
+
+		WithUnmarshalResponseFunc[any](func(httpRes *http.Response, res *rangeResult) error {
+			return readRange(httpRes, writer, start, res)
+		}),
+	)
+
+	req.header = http.Header{
+		"Range": []string{"bytes=" + strconv.FormatInt(start, 10) + "-" + strconv.FormatInt(start+chunkSize-1, 10)},
+	}
+
+	return req
+}
+
+func readRange(httpRes *http.Response, writer io.WriterAt, start int64, res *rangeResult) error {
+	switch httpRes.StatusCode {
+	case http.StatusOK:
+		if start != 0 {
+			return fmt.Errorf("%w: %s (server ignored Range header)", errUnexpectedStatus, httpRes.Status)
+		}
+
+		n, err := io.Copy(io.NewOffsetWriter(writer, 0), httpRes.Body)
+		if err != nil {
+			return fmt.Errorf("write response: %w", err)
+		}
+
+		*res = rangeResult{n: n, total: n, complete: true}
+
+		return nil
+
+	case http.StatusPartialContent:
+		first, last, total, err := parseContentRange(httpRes.Header.Get("Content-Range"))
+		if err != nil {
+			return err
+		}
+
+		if first != start || last < first {
+			return fmt.Errorf("%w: %s", errInvalidContentRange, httpRes.Header.Get("Content-Range"))
+		}
+
+		n, err := io.Copy(io.NewOffsetWriter(writer, start), io.LimitReader(httpRes.Body, last-first+1))
+		if err != nil {
+			return fmt.Errorf("write response: %w", err)
+		}
+
+		*res = rangeResult{n: n, total: total}
+
+		return nil
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		_, _, total, err := parseContentRange(httpRes.Header.Get("Content-Range"))
+		if err != nil || total != start {
+			return fmt.Errorf("%w: %s", errUnexpectedStatus, httpRes.Status)
+		}
+
+		*res = rangeResult{total: total}
+
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %s", errUnexpectedStatus, httpRes.Status)
+	}
+}
+
+// parseContentRange parses a Content-Range header of the form "bytes first-last/total" or "bytes */total".
+// total is -1 if it is unknown. first and last are -1 if the range is unsatisfied.
+func parseContentRange(value string) (int64, int64, int64, error) {
+	rangeSpec, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("%w: %s", errInvalidContentRange, value)
+	}
+
+	rangePart, totalPart, ok := strings.Cut(rangeSpec, "/")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("%w: %s", errInvalidContentRange, value)
+	}
+
+	total := int64(-1)
+
+	if totalPart != "*" {
+		var err error
+		if total, err = strconv.ParseInt(totalPart, 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("%w: %s", errInvalidContentRange, value)
+		}
+	}
+
+	if rangePart == "*" {
+		return -1, -1, total, nil
+	}
+
+	firstPart, lastPart, ok := strings.Cut(rangePart, "-")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("%w: %s", errInvalidContentRange, value)
+	}
+
+	first, err := strconv.ParseInt(firstPart, 10, 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("%w: %s", errInvalidContentRange, value)
+	}
+
+	last, err := strconv.ParseInt(lastPart, 10, 64)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("%w: %s", errInvalidContentRange, value)
+	}
+
+	return first, last, total, nil
+}
@@ -0,0 +1,114 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDownloadRanged(t *testing.T) {
+	is := is.New(t)
+
+	content := bytes.Repeat([]byte("0123456789"), 30)
+
+	var ranges []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		ranges = append(ranges, req.Header.Get("Range"))
+		http.ServeContent(writer, req, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "file.bin"))
+	is.NoErr(err)
+
+	defer file.Close() //nolint:errcheck // test file
+
+	n, err := DownloadRanged(context.Background(), client, server.URL, file, 100)
+	is.NoErr(err)
+	is.Equal(n, int64(len(content)))
+
+	is.Equal(ranges, []string{"bytes=0-99", "bytes=100-199", "bytes=200-299"})
+
+	data, err := os.ReadFile(file.Name())
+	is.NoErr(err)
+	is.Equal(data, content)
+}
+
+func TestDownloadRanged_NoRangeSupport(t *testing.T) {
+	is := is.New(t)
+
+	content := bytes.Repeat([]byte("0123456789"), 30)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write(content)
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "file.bin"))
+	is.NoErr(err)
+
+	defer file.Close() //nolint:errcheck // test file
+
+	n, err := DownloadRanged(context.Background(), client, server.URL, file, 100)
+	is.NoErr(err)
+	is.Equal(n, int64(len(content)))
+
+	data, err := os.ReadFile(file.Name())
+	is.NoErr(err)
+	is.Equal(data, content)
+}
+
+func TestDownloadRanged_ShortChunks(t *testing.T) {
+	is := is.New(t)
+
+	content := bytes.Repeat([]byte("0123456789"), 30)
+
+	const maxChunk = 40
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		var first, last int64
+
+		_, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-%d", &first, &last)
+		is.NoErr(err)
+
+		last = min(last, first+maxChunk-1, int64(len(content))-1)
+
+		writer.Header().Set("Content-Range",
+			"bytes "+strconv.FormatInt(first, 10)+"-"+strconv.FormatInt(last, 10)+"/"+strconv.Itoa(len(content)))
+		writer.WriteHeader(http.StatusPartialContent)
+		_, _ = writer.Write(content[first : last+1])
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "file.bin"))
+	is.NoErr(err)
+
+	defer file.Close() //nolint:errcheck // test file
+
+	n, err := DownloadRanged(context.Background(), client, server.URL, file, 100)
+	is.NoErr(err)
+	is.Equal(n, int64(len(content)))
+
+	data, err := os.ReadFile(file.Name())
+	is.NoErr(err)
+	is.Equal(data, content)
+}
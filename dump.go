@@ -0,0 +1,107 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+)
+
+// maxDumpBodySize is the maximum number of bytes of request and response bodies written by WithDebugDump.
+const maxDumpBodySize = 8 * 1024
+
+// readCloser is an io.ReadCloser that reads from Reader and closes Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// WithDebugDump configures a Client to write a dump of the request and the response of each attempt to writer,
+// including headers and bodies, in the format of httputil.DumpRequestOut and httputil.DumpResponse. This is useful
+// for reverse-engineering APIs. The values of sensitive headers are redacted (see WithRedactedHeaders), and bodies
+// are truncated to 8 KiB. The response body is dumped as received, and remains available for decoding.
+// Responses are dumped even if the attempt fails because of their status code (see WithResponseErrors).
+// Request bodies that are streamed, such as multipart bodies, are not dumped.
+//
+// Each dump is written using a single call to writer.Write. Calls are serialized, so writer does not need to be
+// safe for concurrent use.
+func WithDebugDump(writer io.Writer) ClientOpt {
+	if writer == nil {
+		panic("writer must not be nil")
+	}
+
+	return func(client *Client) {
+		client.debugDump = writer
+	}
+}
+
+// dumpRequest writes a dump of httpReq to the client's debug dump writer.
+func dumpRequest(ctx context.Context, client *Client, httpReq *http.Request) {
+	dumpReq := httpReq.Clone(ctx)
+	dumpReq.Header = redactHeader(httpReq.Header, client.redactedHeaders)
+
+	head, err := httputil.DumpRequestOut(dumpReq, false)
+	if err != nil {
+		return
+	}
+
+	var body []byte
+
+	// streamed bodies are not obtained, since replaying them would rewind the shared reader
+	if httpReq.GetBody != nil && httpReq.ContentLength > 0 {
+		if reader, err := httpReq.GetBody(); err == nil {
+			body, _, _ = readLogBody(reader, maxDumpBodySize)
+			_ = reader.Close()
+		}
+	}
+
+	writeDump(client, head, body)
+}
+
+// dumpResponse writes a dump of httpRes to the client's debug dump writer, and replaces the body of httpRes so that
+// it yields the full response body again. httpRes is modified in place so that trailers received later are still
+// visible to the caller.
+func dumpResponse(client *Client, httpRes *http.Response) {
+	body, _, err := readLogBody(httpRes.Body, maxDumpBodySize)
+
+	var rest io.Reader = httpRes.Body
+	if err != nil {
+		rest = errorReader{err: err}
+	}
+
+	headRes := *httpRes
+	headRes.Header = redactHeader(httpRes.Header, client.redactedHeaders)
+	headRes.Body = nil
+
+	if head, err := httputil.DumpResponse(&headRes, false); err == nil {
+		writeDump(client, head, body)
+	}
+
+	httpRes.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(body), rest),
+		Closer: httpRes.Body,
+	}
+}
+
+// writeDump writes head, followed by body truncated to maxDumpBodySize bytes, to the client's debug dump writer.
+func writeDump(client *Client, head []byte, body []byte) {
+	var buf bytes.Buffer
+
+	buf.Write(head)
+
+	if len(body) > maxDumpBodySize {
+		buf.Write(body[:maxDumpBodySize])
+		buf.WriteString("\n[truncated after " + strconv.Itoa(maxDumpBodySize) + " bytes]")
+	} else {
+		buf.Write(body)
+	}
+
+	buf.WriteString("\n\n")
+
+	client.debugDumpMutex.Lock()
+	defer client.debugDumpMutex.Unlock()
+
+	_, _ = client.debugDump.Write(buf.Bytes())
+}
@@ -0,0 +1,76 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithDebugDump(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Set-Cookie", "session=secret-session")
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{"reply":"Hello, client!"}`))
+	}))
+
+	defer server.Close()
+
+	var dump bytes.Buffer
+
+	client := New(WithDebugDump(&dump))
+
+	req := NewRequest[*testReq, *testRes](server.URL+"/greet", http.MethodPost, &testReq{Message: "Hi"},
+		WithHeader[*testReq, *testRes]("Authorization", "Bearer secret-token"),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+
+	out := dump.String()
+
+	is.True(strings.Contains(out, "POST /greet HTTP/1.1\r\n"))
+	is.True(strings.Contains(out, "Host: "+strings.TrimPrefix(server.URL, "http://")+"\r\n"))
+	is.True(strings.Contains(out, "Authorization: ***\r\n"))
+	is.True(strings.Contains(out, `{"message":"Hi"}`))
+	is.True(strings.Contains(out, "HTTP/1.1 200 OK\r\n"))
+	is.True(strings.Contains(out, "Set-Cookie: ***\r\n"))
+	is.True(strings.Contains(out, `{"reply":"Hello, client!"}`))
+	is.True(!strings.Contains(out, "secret"))
+}
+
+func TestWithDebugDump_ErrorResponse(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = writer.Write([]byte(strings.Repeat("x", maxDumpBodySize+100)))
+	}))
+
+	defer server.Close()
+
+	var dump bytes.Buffer
+
+	client := New(WithDebugDump(&dump), WithResponseErrors(), WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+
+	var resErr *ResponseError
+	is.True(errors.As(err, &resErr))
+	is.Equal(len(resErr.Body), maxDumpBodySize+100)
+
+	out := dump.String()
+
+	is.True(strings.Contains(out, "HTTP/1.1 503 Service Unavailable\r\n"))
+	is.True(strings.Contains(out, "[truncated after 8192 bytes]"))
+}
@@ -0,0 +1,136 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// ErrDuplexRequiresHTTP2 is returned by DoDuplex if the server did not respond using HTTP/2 or later.
+var ErrDuplexRequiresHTTP2 = errors.New("full-duplex requires HTTP/2")
+
+// DoDuplex executes req using client, streaming the request body and the response body simultaneously.
+// reqWriter is called in a separate goroutine to write the request body, while resReader is called to read
+// the response body as soon as the response headers have been received. The request data of req is not sent.
+//
+// DoDuplex has the following constraints:
+//
+//   - Full-duplex streaming requires HTTP/2. The Client's HTTP client must be configured to use HTTP/2, for
+//     example using http.Transport.ForceAttemptHTTP2 with TLS. If the server responds using HTTP/1.x,
+//     DoDuplex returns ErrDuplexRequiresHTTP2 without calling resReader.
+//   - The request is not retried, since the request body cannot be replayed. The Client's retry function and
+//     maximum number of attempts are not used.
+//   - The Client's request timeout is not applied. To stop streaming, cancel ctx.
+//   - When resReader returns, the request body is closed, and any further writes by reqWriter fail.
+//
+// DoDuplex returns the error returned by resReader or reqWriter, if any.
+func DoDuplex[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res],
+	reqWriter func(writer io.Writer) error, resReader func(reader io.Reader) error,
+) error {
+	if err := client.acquire(); err != nil {
+		return err
+	}
+
+	defer client.release()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	duplexReq := *req
+	duplexReq.sendBody = sendBodyOmit
+	duplexReq.multipartParts = nil
+	duplexReq.rawBody = nil
+	duplexReq.rawBodyOpen = nil
+
+	httpReq, err := newHTTPRequest(ctx, client, &duplexReq)
+	if err != nil {
+		return fmt.Errorf("new HTTP request: %w", err)
+	}
+
+	client.logger.InfoContext(ctx, "execute HTTP duplex request",
+		slog.Group("request",
+			slog.String("uri", httpReq.URL.String()),
+			slog.String("method", httpReq.Method),
+		),
+	)
+
+	if req.beforeSend != nil {
+		if err = req.beforeSend(httpReq); err != nil {
+			return fmt.Errorf("before send: %w", err)
+		}
+	}
+
+	bodyReader, bodyWriter := io.Pipe()
+
+	httpReq.Body = bodyReader
+	httpReq.GetBody = nil
+	httpReq.ContentLength = -1
+
+	writeErrCh := make(chan error, 1)
+
+	go func() {
+		err := reqWriter(bodyWriter)
+		_ = bodyWriter.CloseWithError(err)
+		writeErrCh <- err
+	}()
+
+	readErr := duplexResponse(client, req, httpReq, resReader)
+
+	_ = bodyReader.Close()
+
+	writeErr := <-writeErrCh
+
+	if readErr != nil {
+		return readErr
+	}
+
+	if writeErr != nil && !errors.Is(writeErr, io.ErrClosedPipe) {
+		return fmt.Errorf("write request body: %w", writeErr)
+	}
+
+	return nil
+}
+
+func duplexResponse[Req any, Res any](client *Client, req *Request[Req, Res], httpReq *http.Request,
+	resReader func(reader io.Reader) error,
+) error {
+	httpRes, err := executeHTTPRequest(client, httpReq, req.method, requestURI(client, req))
+	if err != nil {
+		return fmt.Errorf("execute HTTP request: %w", err)
+	}
+
+	defer httpRes.Body.Close() //nolint:errcheck // we're only reading
+
+	if httpRes.ProtoMajor < 2 {
+		return ErrDuplexRequiresHTTP2
+	}
+
+	assumeContentType(httpRes, client.assumeContentType)
+
+	for _, m := range client.responseMiddlewares {
+		if err = m(httpRes); err != nil {
+			return fmt.Errorf("response middleware: %w", err)
+		}
+	}
+
+	if !isSuccess(httpRes.StatusCode) && client.responseErrors {
+		return newResponseError(httpRes, client.maxResponseBodySize)
+	}
+
+	decodeRes := httpRes
+
+	if requestDecompression(client, req) {
+		if decodeRes, err = decompress(httpRes); err != nil {
+			return fmt.Errorf("decompress response: %w", err)
+		}
+	}
+
+	if err = resReader(decodeRes.Body); err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package gojsonclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDoDuplex(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.ProtoMajor, 2)
+
+		writer.WriteHeader(http.StatusOK)
+		writer.(http.Flusher).Flush()
+
+		scanner := bufio.NewScanner(req.Body)
+		for scanner.Scan() {
+			_, _ = writer.Write([]byte(strings.ToUpper(scanner.Text()) + "\n"))
+			writer.(http.Flusher).Flush()
+		}
+	}))
+
+	server.EnableHTTP2 = true
+	server.StartTLS()
+
+	defer server.Close()
+
+	client := New(WithHTTPClient(server.Client()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, nil)
+
+	echoed := make(chan string)
+
+	var lines []string
+
+	err := DoDuplex(context.Background(), client, req,
+		func(writer io.Writer) error {
+			for _, line := range []string{"hello", "world"} {
+				if _, err := io.WriteString(writer, line+"\n"); err != nil {
+					return err
+				}
+
+				// wait for the echo before sending the next line, which only works with full-duplex
+				<-echoed
+			}
+
+			return nil
+		},
+
+		func(reader io.Reader) error {
+			scanner := bufio.NewScanner(reader)
+			for scanner.Scan() {
+				lines = append(lines, scanner.Text())
+				echoed <- scanner.Text()
+			}
+
+			return scanner.Err()
+		},
+	)
+
+	is.NoErr(err)
+	is.Equal(lines, []string{"HELLO", "WORLD"})
+}
+
+func TestDoDuplex_HTTP1(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New()
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, nil)
+
+	err := DoDuplex(context.Background(), client, req,
+		func(_ io.Writer) error {
+			return nil
+		},
+
+		func(_ io.Reader) error {
+			is.Fail()
+			return nil
+		},
+	)
+
+	is.True(errors.Is(err, ErrDuplexRequiresHTTP2))
+}
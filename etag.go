@@ -0,0 +1,50 @@
+package gojsonclient
+
+import "net/http"
+
+// ETagMismatchError is returned when the ETag header of the response does not match the ETag expected by a Request
+// that has been configured using WithExpectETag.
+type ETagMismatchError struct {
+	// Expected is the expected ETag.
+	Expected string
+
+	// Actual is the ETag header of the response, or empty if the response has no ETag header.
+	Actual string
+}
+
+var _ error = (*ETagMismatchError)(nil)
+
+// WithExpectETag configures a Request to compare the ETag header of the response to etag, and to return
+// an *ETagMismatchError if they are not equal. The comparison is exact, so etag must include quotes and
+// a weak validator prefix (W/) where applicable. The response body is not decoded on mismatch.
+//
+// Like other errors, an *ETagMismatchError is subject to the retry function.
+func WithExpectETag[Req any, Res any](etag string) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.expectETag = etag
+	}
+}
+
+func checkETag(httpRes *http.Response, expected string) error {
+	if expected == "" {
+		return nil
+	}
+
+	if actual := httpRes.Header.Get("ETag"); actual != expected {
+		return &ETagMismatchError{
+			Expected: expected,
+			Actual:   actual,
+		}
+	}
+
+	return nil
+}
+
+// Error implements error.
+func (e *ETagMismatchError) Error() string {
+	if e.Actual == "" {
+		return "ETag mismatch: expected " + e.Expected + ", got none"
+	}
+
+	return "ETag mismatch: expected " + e.Expected + ", got " + e.Actual
+}
@@ -0,0 +1,39 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestResponse_ExpectETag(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithExpectETag[any, *testRes](`"v2"`),
+	)
+
+	newHTTPRes := func(etag string) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "OK",
+			Header:     http.Header{"Etag": []string{etag}},
+			Body:       io.NopCloser(bytes.NewReader([]byte(`{"reply":"Hello, client!"}`))),
+		}
+	}
+
+	res, err := response(New(), newHTTPRes(`"v2"`), req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+
+	_, err = response(New(), newHTTPRes(`"v1"`), req)
+
+	var mismatchErr *ETagMismatchError
+	is.True(errors.As(err, &mismatchErr))
+	is.Equal(mismatchErr.Expected, `"v2"`)
+	is.Equal(mismatchErr.Actual, `"v1"`)
+}
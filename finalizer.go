@@ -0,0 +1,82 @@
+package gojsonclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// FinalizerFunc is a function that is called once after a call to Do has completed.
+type FinalizerFunc func(ctx context.Context, info *FinalizeInfo)
+
+// FinalizeInfo describes the outcome of a call to Do. Since finalizers are configured on the Client and are
+// therefore not generic, the decoded response data is not included.
+type FinalizeInfo struct {
+	// Method is the HTTP request method.
+	Method string
+
+	// URI is the request URI, including the client's base URI.
+	URI string
+
+	// StatusCode is the HTTP response status code of the last attempt, or 0 if no response was received.
+	StatusCode int
+
+	// Attempts is the number of attempts that have been made.
+	Attempts int
+
+	// Duration is the total time it took to execute the request, including all attempts and delays between them.
+	Duration time.Duration
+
+	// Err is the error returned by Do, if any.
+	Err error
+}
+
+// WithFinalizer configures a Client to call fun once after each call to Do has completed, regardless of whether
+// it succeeded or failed. In contrast to the functions added using WithOnAttempt, fun is not called for each
+// attempt. This is useful for cleanup or metrics that should always run.
+// Any number of functions may be added. They are called in the order they were added.
+//
+// fun is not called if Do fails because the Client has been shut down.
+func WithFinalizer(fun FinalizerFunc) ClientOpt {
+	if fun == nil {
+		panic("fun must not be nil")
+	}
+
+	return func(client *Client) {
+		client.finalizers = append(client.finalizers, fun)
+	}
+}
+
+// newFinalizeInfo returns the information to pass to the client's finalizers, or nil if client has no finalizers.
+func newFinalizeInfo[Req any, Res any](client *Client, req *Request[Req, Res], lastHTTPRes *http.Response,
+	attempts int, duration time.Duration, err error,
+) *FinalizeInfo {
+	if len(client.finalizers) == 0 {
+		return nil
+	}
+
+	info := FinalizeInfo{
+		Method:   req.method,
+		URI:      requestURI(client, req),
+		Attempts: attempts,
+		Duration: duration,
+		Err:      err,
+	}
+
+	if lastHTTPRes != nil {
+		info.StatusCode = lastHTTPRes.StatusCode
+	}
+
+	return &info
+}
+
+// notifyFinalizers calls the client's finalizers with info, unless info is nil.
+func notifyFinalizers(ctx context.Context, client *Client, info *FinalizeInfo) {
+	if info == nil {
+		return
+	}
+
+	for _, fun := range client.finalizers {
+		fun(ctx, info)
+	}
+}
@@ -0,0 +1,83 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithFinalizer(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = writer.Write([]byte(`{"reply":"hello"}`))
+	}))
+
+	defer server.Close()
+
+	var infos []FinalizeInfo
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithResponseErrors(),
+
+		WithFinalizer(func(_ context.Context, info *FinalizeInfo) {
+			infos = append(infos, *info)
+		}),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(len(infos), 1)
+	is.Equal(infos[0].Method, http.MethodGet)
+	is.Equal(infos[0].URI, server.URL)
+	is.Equal(infos[0].StatusCode, http.StatusOK)
+	is.Equal(infos[0].Attempts, 2)
+	is.NoErr(infos[0].Err)
+}
+
+func TestWithFinalizer_Error(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	var infos []FinalizeInfo
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithResponseErrors(),
+		WithMaxAttempts(3),
+
+		WithFinalizer(func(_ context.Context, info *FinalizeInfo) {
+			infos = append(infos, *info)
+		}),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+	is.True(err != nil)
+	is.Equal(len(infos), 1)
+	is.Equal(infos[0].StatusCode, http.StatusServiceUnavailable)
+	is.Equal(infos[0].Attempts, 3)
+	is.True(errors.Is(infos[0].Err, err))
+}
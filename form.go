@@ -0,0 +1,158 @@
+package gojsonclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var errUnsupportedFormType = errors.New("unsupported form type")
+
+// WithFormRequestBody configures a Request to encode the request data as application/x-www-form-urlencoded
+// using FormMarshal, and to set the Content-Type header accordingly.
+func WithFormRequestBody[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.marshalRequest = FormMarshal[Req]
+		req.contentType = "application/x-www-form-urlencoded"
+	}
+}
+
+// FormMarshal encodes val as application/x-www-form-urlencoded and outputs it to writer.
+//
+// val may be a url.Values, a map[string]string, or a struct (or a pointer to any of those). For structs,
+// each exported field is encoded using the name given in its "form" tag, or the field name if there is no tag.
+// Fields tagged with "-" are skipped, and fields tagged with the "omitempty" option are skipped if they have
+// their zero value. Fields may be strings, booleans, numbers, fmt.Stringers, or slices of those.
+func FormMarshal[T any](writer io.Writer, val T) error {
+	values, err := formValues(reflect.ValueOf(val))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(writer, values.Encode())
+
+	return err //nolint:wrapcheck // we don't add new info here
+}
+
+func formValues(val reflect.Value) (url.Values, error) {
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return url.Values{}, nil
+		}
+
+		val = val.Elem()
+	}
+
+	switch vals := val.Interface().(type) {
+	case url.Values:
+		return vals, nil
+
+	case map[string][]string:
+		return url.Values(vals), nil
+
+	case map[string]string:
+		values := url.Values{}
+		for key, v := range vals {
+			values.Set(key, v)
+		}
+
+		return values, nil
+	}
+
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedFormType, val.Type())
+	}
+
+	return structFormValues(val)
+}
+
+func structFormValues(val reflect.Value) (url.Values, error) {
+	values := url.Values{}
+
+	for i := range val.NumField() {
+		field := val.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldVal := val.Field(i)
+		if opts == "omitempty" && fieldVal.IsZero() {
+			continue
+		}
+
+		strs, err := formStrings(fieldVal)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		values[name] = append(values[name], strs...)
+	}
+
+	return values, nil
+}
+
+func formStrings(val reflect.Value) ([]string, error) {
+	if val.Kind() == reflect.Slice && val.Type().Elem().Kind() != reflect.Uint8 {
+		strs := make([]string, 0, val.Len())
+
+		for i := range val.Len() {
+			str, err := formString(val.Index(i))
+			if err != nil {
+				return nil, err
+			}
+
+			strs = append(strs, str)
+		}
+
+		return strs, nil
+	}
+
+	str, err := formString(val)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{str}, nil
+}
+
+func formString(val reflect.Value) (string, error) {
+	if stringer, ok := val.Interface().(fmt.Stringer); ok {
+		return stringer.String(), nil
+	}
+
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return "", nil
+		}
+
+		val = val.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.String:
+		return val.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'f', -1, val.Type().Bits()), nil
+	default:
+		return "", fmt.Errorf("%w: %s", errUnsupportedFormType, val.Type())
+	}
+}
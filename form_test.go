@@ -0,0 +1,66 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestFormMarshal(t *testing.T) {
+	is := is.New(t)
+
+	type form struct {
+		GrantType string   `form:"grant_type"`
+		Scopes    []string `form:"scope"`
+		Count     int      `form:"count,omitempty"`
+		Enabled   bool
+		Ignored   string `form:"-"`
+	}
+
+	buf := bytes.Buffer{}
+
+	err := FormMarshal(&buf, &form{
+		GrantType: "client_credentials",
+		Scopes:    []string{"read", "write"},
+		Enabled:   true,
+		Ignored:   "foo",
+	})
+	is.NoErr(err)
+
+	values, err := url.ParseQuery(buf.String())
+	is.NoErr(err)
+	is.Equal(values, url.Values{
+		"grant_type": []string{"client_credentials"},
+		"scope":      []string{"read", "write"},
+		"Enabled":    []string{"true"},
+	})
+}
+
+func TestDo_FormRequestBody(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded")
+		is.NoErr(req.ParseForm())
+		is.Equal(req.PostForm.Get("grant_type"), "client_credentials")
+
+		_, _ = writer.Write([]byte(`{"reply":"token"}`))
+	}))
+
+	defer server.Close()
+
+	client := New()
+
+	req := NewRequest(server.URL, http.MethodPost, url.Values{"grant_type": []string{"client_credentials"}},
+		WithFormRequestBody[url.Values, *testRes](),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "token")
+}
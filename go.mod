@@ -8,4 +8,8 @@ require (
 	github.com/blizzy78/gobackoff v0.2.1
 	github.com/go-json-experiment/json v0.0.0-20231102232822-2e55bd4e08b0
 	github.com/matryer/is v1.4.1
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	go.opentelemetry.io/otel v1.32.0
 )
+
+require go.opentelemetry.io/otel/trace v1.32.0 // indirect
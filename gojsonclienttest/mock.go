@@ -0,0 +1,166 @@
+// Package gojsonclienttest provides utilities for testing code that uses gojsonclient.
+package gojsonclienttest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/blizzy78/gojsonclient"
+	"github.com/go-json-experiment/json"
+)
+
+// MockClient provides a gojsonclient.Client that does not make real HTTP requests, but returns canned
+// responses registered using Handle. All requests received are recorded for later assertions.
+//
+// MockClient is safe to use concurrently.
+type MockClient struct {
+	// Client is the gojsonclient.Client that uses the canned responses.
+	Client *gojsonclient.Client
+
+	mutex     sync.Mutex
+	responses map[string]*MockResponse
+	requests  []*RecordedRequest
+}
+
+// MockResponse is a canned response returned by a MockClient.
+type MockResponse struct {
+	// StatusCode is the HTTP response status code. If 0, http.StatusOK is used.
+	StatusCode int
+
+	// Header contains the HTTP response headers.
+	Header http.Header
+
+	// Body is encoded to JSON and returned as the response body, unless RawBody is set.
+	Body any
+
+	// RawBody is returned verbatim as the response body.
+	RawBody []byte
+}
+
+// RecordedRequest is a request received by a MockClient.
+type RecordedRequest struct {
+	// Method is the HTTP request method.
+	Method string
+
+	// URL is the full request URL.
+	URL string
+
+	// Path is the path of the request URL.
+	Path string
+
+	// Header contains the HTTP request headers.
+	Header http.Header
+
+	// Body is the raw request body.
+	Body []byte
+}
+
+var _ http.RoundTripper = (*MockClient)(nil)
+
+// NewMockClient creates a new MockClient. The gojsonclient.Client is created using opts, but always uses
+// the MockClient as its HTTP transport. Requests for which no response has been registered receive an
+// http.StatusNotFound response.
+func NewMockClient(opts ...gojsonclient.ClientOpt) *MockClient {
+	mock := MockClient{
+		responses: map[string]*MockResponse{},
+	}
+
+	opts = append(opts, gojsonclient.WithHTTPClient(&http.Client{
+		Transport: &mock,
+	}))
+
+	mock.Client = gojsonclient.New(opts...)
+
+	return &mock
+}
+
+// Handle registers res as the response for requests with the given method and URL path.
+// A previously registered response for the same method and path is replaced.
+func (m *MockClient) Handle(method string, path string, res *MockResponse) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.responses[method+" "+path] = res
+}
+
+// Requests returns all requests received so far, in order.
+func (m *MockClient) Requests() []*RecordedRequest {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]*RecordedRequest(nil), m.requests...)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (m *MockClient) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+
+		body, err = io.ReadAll(req.Body)
+
+		// like any http.RoundTripper, we must close the request body, even on errors
+		_ = req.Body.Close()
+
+		if err != nil {
+			return nil, err //nolint:wrapcheck // we don't add new info here
+		}
+	}
+
+	m.mutex.Lock()
+
+	m.requests = append(m.requests, &RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Path:   req.URL.Path,
+		Header: req.Header.Clone(),
+		Body:   body,
+	})
+
+	mockRes, ok := m.responses[req.Method+" "+req.URL.Path]
+
+	m.mutex.Unlock()
+
+	if !ok {
+		return newHTTPResponse(req, http.StatusNotFound, http.Header{}, []byte("Not Found")), nil
+	}
+
+	resBody := mockRes.RawBody
+
+	if resBody == nil && mockRes.Body != nil {
+		var err error
+		if resBody, err = json.Marshal(mockRes.Body); err != nil {
+			return nil, err //nolint:wrapcheck // we don't add new info here
+		}
+	}
+
+	statusCode := mockRes.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	header := mockRes.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return newHTTPResponse(req, statusCode, header, resBody), nil
+}
+
+func newHTTPResponse(req *http.Request, statusCode int, header http.Header, body []byte) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,104 @@
+package gojsonclienttest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/blizzy78/gojsonclient"
+	"github.com/matryer/is"
+)
+
+type testReq struct {
+	Message string `json:"message"`
+}
+
+type testRes struct {
+	Reply string `json:"reply"`
+}
+
+type closeTrackingReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeTrackingReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestMockClient(t *testing.T) {
+	is := is.New(t)
+
+	mock := NewMockClient(gojsonclient.WithBaseURI("https://www.example.com"))
+
+	mock.Handle(http.MethodPost, "/foo", &MockResponse{
+		StatusCode: http.StatusCreated,
+		Header:     http.Header{"X-Request-Id": []string{"123"}},
+		Body:       &testRes{Reply: "Hello, client!"},
+	})
+
+	req := gojsonclient.NewRequest[*testReq, *testRes]("/foo", http.MethodPost, &testReq{Message: "Hello, server!"})
+
+	res, err := gojsonclient.Do(context.Background(), mock.Client, req)
+	is.NoErr(err)
+	is.Equal(res.StatusCode, http.StatusCreated)
+	is.Equal(res.Status, "201 Created")
+	is.Equal(res.Header.Get("X-Request-Id"), "123")
+	is.Equal(res.Res.Reply, "Hello, client!")
+
+	requests := mock.Requests()
+	is.Equal(len(requests), 1)
+	is.Equal(requests[0].Method, http.MethodPost)
+	is.Equal(requests[0].URL, "https://www.example.com/foo")
+	is.Equal(string(requests[0].Body), `{"message":"Hello, server!"}`)
+}
+
+func TestMockClient_NotFound(t *testing.T) {
+	is := is.New(t)
+
+	mock := NewMockClient(
+		gojsonclient.WithMaxAttempts(1),
+		gojsonclient.WithResponseErrors(),
+	)
+
+	req := gojsonclient.NewRequest[*testReq, *testRes]("https://www.example.com/foo", http.MethodGet, nil)
+
+	_, err := gojsonclient.Do(context.Background(), mock.Client, req)
+	is.True(err != nil)
+
+	is.Equal(len(mock.Requests()), 1)
+}
+
+func TestMockClient_RoundTrip_ClosesBody(t *testing.T) {
+	is := is.New(t)
+
+	mock := NewMockClient()
+
+	body := &closeTrackingReader{Reader: strings.NewReader("data")}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "https://www.example.com/foo", body)
+	is.NoErr(err)
+
+	res, err := mock.RoundTrip(req)
+	is.NoErr(err)
+
+	_ = res.Body.Close()
+
+	is.True(body.closed)
+
+	errRead := errors.New("read")
+
+	body = &closeTrackingReader{Reader: iotest.ErrReader(errRead)}
+
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodPost, "https://www.example.com/foo", body)
+	is.NoErr(err)
+
+	_, err = mock.RoundTrip(req) //nolint:bodyclose // no response on error
+	is.True(errors.Is(err, errRead))
+	is.True(body.closed)
+}
@@ -0,0 +1,166 @@
+package gojsonclient
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RoundTripInfo describes a single HTTP round trip made by a transport wrapped using InstrumentedTransport.
+type RoundTripInfo struct {
+	// Method is the HTTP request method.
+	Method string
+
+	// URL is the request URL.
+	URL string
+
+	// StatusCode is the HTTP response status code, or 0 if no response was received.
+	StatusCode int
+
+	// Duration is the time it took until the response headers were received, or until the round trip failed.
+	Duration time.Duration
+
+	// RequestBytes is the number of request body bytes sent.
+	RequestBytes int64
+
+	// ResponseBytes is the number of response body bytes read.
+	ResponseBytes int64
+
+	// Err is the error returned by the round trip, if any.
+	Err error
+}
+
+// RoundTripFunc is a function that is called after an HTTP round trip has completed.
+type RoundTripFunc func(info RoundTripInfo)
+
+type instrumentedTransport struct {
+	base        http.RoundTripper
+	onRoundTrip RoundTripFunc
+}
+
+type countingReadCloser struct {
+	body  io.ReadCloser
+	count atomic.Int64
+}
+
+type instrumentedBody struct {
+	countingReadCloser
+
+	once    sync.Once
+	info    RoundTripInfo
+	reqBody *countingReadCloser
+	onDone  RoundTripFunc
+}
+
+var (
+	_ http.RoundTripper = (*instrumentedTransport)(nil)
+	_ io.ReadCloser     = (*countingReadCloser)(nil)
+	_ io.ReadCloser     = (*instrumentedBody)(nil)
+)
+
+// InstrumentedTransport returns an http.RoundTripper that wraps base and calls onRoundTrip for each HTTP round trip.
+// If base is nil, http.DefaultTransport is used.
+//
+// Since the number of response body bytes is only known after the response body has been read, onRoundTrip is
+// called when the response body is closed. If the round trip fails, onRoundTrip is called immediately.
+//
+// In contrast to the OnAttemptFunc configured using WithOnAttempt, onRoundTrip is called for every request sent
+// over the wire, including redirects.
+func InstrumentedTransport(base http.RoundTripper, onRoundTrip RoundTripFunc) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &instrumentedTransport{
+		base:        base,
+		onRoundTrip: onRoundTrip,
+	}
+}
+
+// WithInstrumentedTransport configures a Client to wrap its HTTP client's transport using InstrumentedTransport.
+//
+// WithInstrumentedTransport modifies the client's HTTP client in the same way as WithCookieJar.
+func WithInstrumentedTransport(onRoundTrip RoundTripFunc) ClientOpt {
+	return func(client *Client) {
+		client.httpClientOpts = append(client.httpClientOpts, func(httpClient *http.Client) {
+			httpClient.Transport = InstrumentedTransport(httpClient.Transport, onRoundTrip)
+		})
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody *countingReadCloser
+
+	if req.Body != nil && req.Body != http.NoBody {
+		reqBody = &countingReadCloser{body: req.Body}
+
+		req = req.Clone(req.Context())
+		req.Body = reqBody
+	}
+
+	start := time.Now()
+
+	res, err := t.base.RoundTrip(req)
+
+	info := RoundTripInfo{
+		Method:   req.Method,
+		URL:      req.URL.String(),
+		Duration: time.Since(start),
+		Err:      err,
+	}
+
+	if err != nil {
+		if reqBody != nil {
+			info.RequestBytes = reqBody.count.Load()
+		}
+
+		t.onRoundTrip(info)
+
+		return nil, err //nolint:wrapcheck // we don't add new info here
+	}
+
+	info.StatusCode = res.StatusCode
+
+	res.Body = &instrumentedBody{
+		countingReadCloser: countingReadCloser{body: res.Body},
+		info:               info,
+		reqBody:            reqBody,
+		onDone:             t.onRoundTrip,
+	}
+
+	return res, nil
+}
+
+// Read implements io.Reader.
+func (c *countingReadCloser) Read(buf []byte) (int, error) {
+	n, err := c.body.Read(buf)
+	c.count.Add(int64(n))
+
+	return n, err //nolint:wrapcheck // we don't add new info here
+}
+
+// Close implements io.Closer.
+func (c *countingReadCloser) Close() error {
+	return c.body.Close() //nolint:wrapcheck // we don't add new info here
+}
+
+// Close implements io.Closer.
+func (b *instrumentedBody) Close() error {
+	err := b.countingReadCloser.Close()
+
+	b.once.Do(func() {
+		info := b.info
+		info.ResponseBytes = b.count.Load()
+
+		if b.reqBody != nil {
+			info.RequestBytes = b.reqBody.count.Load()
+		}
+
+		b.onDone(info)
+	})
+
+	return err
+}
@@ -0,0 +1,46 @@
+package gojsonclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/matryer/is"
+)
+
+func TestWithInstrumentedTransport(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+
+		writer.WriteHeader(http.StatusCreated)
+		_ = json.MarshalWrite(writer, &testRes{Reply: "Hello, client!"})
+	}))
+
+	defer server.Close()
+
+	var infos []RoundTripInfo
+
+	client := New(
+		WithInstrumentedTransport(func(info RoundTripInfo) {
+			infos = append(infos, info)
+		}),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: "Hello, server!"})
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(len(infos), 1)
+	is.Equal(infos[0].Method, http.MethodPost)
+	is.Equal(infos[0].StatusCode, http.StatusCreated)
+	is.True(infos[0].Duration >= 10*time.Millisecond)
+	is.Equal(infos[0].RequestBytes, int64(len(`{"message":"Hello, server!"}`)))
+	is.Equal(infos[0].ResponseBytes, int64(len(`{"reply":"Hello, client!"}`)))
+	is.NoErr(infos[0].Err)
+}
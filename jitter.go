@@ -0,0 +1,172 @@
+package gojsonclient
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/blizzy78/gobackoff"
+)
+
+// lockedRand generates random numbers using rand, or using the global random number generator if rand is nil.
+type lockedRand struct {
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+// decorrelatedJitter computes delays between attempts using decorrelated jitter.
+type decorrelatedJitter struct {
+	lockedRand
+
+	base     time.Duration
+	maxDelay time.Duration
+}
+
+// clockBackoff computes exponentially increasing delays between attempts, in the same way as gobackoff.New(),
+// so that they can be waited for using the client's clock.
+type clockBackoff struct {
+	lockedRand
+}
+
+// jitterState is the state of decorrelated jitter or clock backoff for a single call to Do.
+type jitterState struct {
+	jitter  *decorrelatedJitter
+	backoff *clockBackoff
+	prev    time.Duration
+}
+
+// WithDecorrelatedJitter configures a Client to delay new attempts using decorrelated jitter instead of the
+// backoff configured using WithBackoff. The delay before each new attempt is a random duration between base and
+// three times the previous delay, but at most maxDelay:
+//
+//	delay = min(maxDelay, random(base, previousDelay*3))
+//
+// The previous delay of the first new attempt is base. Since delays are spread more evenly than using a fixed
+// jitter, decorrelated jitter helps preventing many clients from retrying at the same time.
+//
+// If the server has requested a longer delay (see WithRespectRetryAfter and WithRateLimitError), that delay
+// is used instead. Delays are measured using the client's clock (see WithClock). Random numbers are generated
+// using the source configured using WithJitterSource, if any.
+func WithDecorrelatedJitter(base time.Duration, maxDelay time.Duration) ClientOpt {
+	if base <= 0 {
+		panic("base must be >0")
+	}
+
+	if maxDelay < base {
+		panic("maxDelay must be >=base")
+	}
+
+	return func(client *Client) {
+		client.jitter = &decorrelatedJitter{
+			base:     base,
+			maxDelay: maxDelay,
+		}
+	}
+}
+
+// WithJitterSource configures a Client to use src to generate random numbers for decorrelated jitter (see
+// WithDecorrelatedJitter), or for the delays between attempts if a clock has been configured (see WithClock). This can be used to make delays deterministic in tests. By default, the global
+// random number generator of package math/rand/v2 is used.
+func WithJitterSource(src rand.Source) ClientOpt {
+	if src == nil {
+		panic("src must not be nil")
+	}
+
+	return func(client *Client) {
+		client.jitterSource = src
+	}
+}
+
+// applyJitterOpts configures the client's decorrelated jitter, or the clock backoff if a clock has been
+// configured using WithClock.
+func (c *Client) applyJitterOpts() {
+	var rnd *rand.Rand
+	if c.jitterSource != nil {
+		rnd = rand.New(c.jitterSource) //nolint:gosec // no need for cryptographically secure random numbers
+	}
+
+	_, systemTime := c.clock.(systemClock)
+
+	switch {
+	case c.jitter != nil:
+		c.jitter.rand = rnd
+
+	case !systemTime:
+		c.clockBackoff = &clockBackoff{
+			lockedRand: lockedRand{
+				rand: rnd,
+			},
+		}
+
+	default:
+		return
+	}
+
+	// delays are introduced by the client itself
+	c.backoff = gobackoff.New(
+		gobackoff.WithInitialDelay(time.Nanosecond),
+		gobackoff.WithMaxDelay(time.Nanosecond),
+		gobackoff.WithJitter(0.0),
+	)
+}
+
+// newJitterState returns a new jitterState for a single call to Do.
+func (c *Client) newJitterState() *jitterState {
+	return &jitterState{
+		jitter:  c.jitter,
+		backoff: c.clockBackoff,
+	}
+}
+
+// next returns the delay before the next attempt. It returns 0 if neither decorrelated jitter nor clock backoff
+// is used.
+func (s *jitterState) next() time.Duration {
+	switch {
+	case s.jitter != nil:
+		s.prev = s.jitter.delay(max(s.prev, s.jitter.base))
+		return s.prev
+
+	case s.backoff != nil:
+		s.prev = s.backoff.nextBase(s.prev)
+		return s.backoff.delay(s.prev)
+
+	default:
+		return 0
+	}
+}
+
+// nextBase returns the base delay following prev, which is 0 before the first new attempt.
+func (b *clockBackoff) nextBase(prev time.Duration) time.Duration {
+	if prev == 0 {
+		return clockBackoffInitialDelay
+	}
+
+	return min(time.Duration(float64(prev)*clockBackoffMultiplier), clockBackoffMaxDelay)
+}
+
+// delay returns a random delay between base/2 and base*1.5, but at most clockBackoffMaxDelay.
+func (b *clockBackoff) delay(base time.Duration) time.Duration {
+	return min(base/2+time.Duration(b.int64N(int64(base))), clockBackoffMaxDelay)
+}
+
+// delay returns a random delay between j.base and prev*3, but at most j.maxDelay.
+func (j *decorrelatedJitter) delay(prev time.Duration) time.Duration {
+	upper := min(prev*3, j.maxDelay)
+	if prev > j.maxDelay/3 {
+		// prevent overflow
+		upper = j.maxDelay
+	}
+
+	return min(j.base+time.Duration(j.int64N(int64(upper-j.base)+1)), j.maxDelay)
+}
+
+func (r *lockedRand) int64N(n int64) int64 {
+	if r.rand == nil {
+		return rand.Int64N(n) //nolint:gosec // no need for cryptographically secure random numbers
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.rand.Int64N(n)
+}
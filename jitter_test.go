@@ -0,0 +1,84 @@
+package gojsonclient
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestJitterState_Next(t *testing.T) {
+	is := is.New(t)
+
+	const (
+		base     = 100 * time.Millisecond
+		maxDelay = 2 * time.Second
+	)
+
+	state := jitterState{
+		jitter: &decorrelatedJitter{
+			lockedRand: lockedRand{
+				rand: rand.New(rand.NewPCG(1, 2)), //nolint:gosec // no need for cryptographically secure random numbers
+			},
+
+			base:     base,
+			maxDelay: maxDelay,
+		},
+	}
+
+	prev := base
+
+	for range 1000 {
+		delay := state.next()
+
+		is.True(delay >= base)
+		is.True(delay <= min(prev*3, maxDelay))
+
+		prev = delay
+	}
+}
+
+func TestWithDecorrelatedJitter(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "Service Unavailable", http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	sleeps := func() []time.Duration {
+		clock := newFakeClock()
+
+		client := New(
+			WithMaxAttempts(6),
+			WithClock(clock),
+			WithDecorrelatedJitter(100*time.Millisecond, time.Second),
+			WithJitterSource(rand.NewPCG(1, 2)),
+		)
+
+		req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+		_, err := Do(context.Background(), client, req)
+		is.True(err != nil)
+
+		return clock.sleeps
+	}
+
+	first := sleeps()
+	is.Equal(len(first), 5)
+	is.Equal(sleeps(), first)
+
+	prev := 100 * time.Millisecond
+
+	for _, delay := range first {
+		is.True(delay >= 100*time.Millisecond)
+		is.True(delay <= min(prev*3, time.Second))
+
+		prev = delay
+	}
+}
@@ -0,0 +1,86 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WithStripJSONComments configures a Request to remove comments from the response body before decoding it, so
+// that JSON with comments (JSONC) can be decoded. Both line comments (// ...) and block comments (/* ... */) are
+// removed. Comment markers inside string literals are left untouched. The response body is buffered in memory.
+func WithStripJSONComments[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.stripComments = true
+	}
+}
+
+// stripResponseComments returns a copy of httpRes whose body yields the response body without comments.
+func stripResponseComments(httpRes *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	strippedRes := *httpRes
+	strippedRes.Body = io.NopCloser(bytes.NewReader(stripJSONComments(body)))
+
+	return &strippedRes, nil
+}
+
+// stripJSONComments returns data without comments. Block comments are replaced by a single space, so that
+// they still separate adjacent tokens. An unterminated block comment extends to the end of data.
+func stripJSONComments(data []byte) []byte {
+	stripped := make([]byte, 0, len(data))
+
+	inString := false
+
+	for idx := 0; idx < len(data); idx++ {
+		char := data[idx]
+
+		switch {
+		case inString:
+			stripped = append(stripped, char)
+
+			switch char {
+			case '\\':
+				if idx+1 < len(data) {
+					idx++
+					stripped = append(stripped, data[idx])
+				}
+
+			case '"':
+				inString = false
+			}
+
+		case char == '"':
+			inString = true
+
+			stripped = append(stripped, char)
+
+		case char == '/' && idx+1 < len(data) && data[idx+1] == '/':
+			end := bytes.IndexByte(data[idx:], '\n')
+			if end < 0 {
+				return stripped
+			}
+
+			// keep the newline
+			idx += end - 1
+
+		case char == '/' && idx+1 < len(data) && data[idx+1] == '*':
+			end := bytes.Index(data[idx+2:], []byte("*/"))
+			if end < 0 {
+				return append(stripped, ' ')
+			}
+
+			stripped = append(stripped, ' ')
+			idx += 2 + end + 1
+
+		default:
+			stripped = append(stripped, char)
+		}
+	}
+
+	return stripped
+}
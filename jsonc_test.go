@@ -0,0 +1,56 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestStripJSONComments(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"none", `{"a":1}`, `{"a":1}`},
+		{"line", "{\"a\":1 // comment\n}", "{\"a\":1 \n}"},
+		{"line at end", `{"a":1} // comment`, `{"a":1} `},
+		{"block", `{/* comment */"a":1}`, `{ "a":1}`},
+		{"block multiline", "{\"a\":/* x\ny */1}", `{"a": 1}`},
+		{"unterminated block", `{"a":1} /* comment`, `{"a":1}  `},
+		{"in string", `{"a":"// not /* a */ comment"}`, `{"a":"// not /* a */ comment"}`},
+		{"escaped quote", `{"a":"\"//"} // comment`, `{"a":"\"//"} `},
+		{"escaped backslash", `{"a":"\\"} // comment`, `{"a":"\\"} `},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(string(stripJSONComments([]byte(test.data))), test.want)
+		})
+	}
+}
+
+func TestResponse_StripJSONComments(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithStripJSONComments[any, *testRes](),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "OK",
+		Body: io.NopCloser(bytes.NewReader([]byte(`{
+			// the reply
+			"reply": /* greeting */ "Hello, // client!"
+		}`))),
+	}
+
+	res, err := response(New(), &httpRes, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, // client!")
+}
@@ -0,0 +1,129 @@
+package gojsonclient
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// ResponseBodyTooLargeError is returned when the response body exceeds the maximum size configured using
+// WithMaxResponseBodySize.
+type ResponseBodyTooLargeError struct {
+	// Limit is the maximum response body size in bytes.
+	Limit int64
+}
+
+// RequestTooLargeError is returned when the marshaled request body exceeds the maximum size configured using
+// WithMaxRequestBytes. The request is not sent.
+type RequestTooLargeError struct {
+	// Size is the size of the marshaled request body in bytes.
+	Size int64
+
+	// Limit is the maximum request body size in bytes.
+	Limit int64
+}
+
+// limitedBody is an io.ReadCloser that reads at most limit bytes and records whether the underlying
+// body contains more data.
+type limitedBody struct {
+	body     io.ReadCloser
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+var (
+	_ error         = (*ResponseBodyTooLargeError)(nil)
+	_ error         = (*RequestTooLargeError)(nil)
+	_ io.ReadCloser = (*limitedBody)(nil)
+)
+
+// WithMaxResponseBodySize configures a Client to read at most maxSize bytes of each response body.
+// If a response body is larger, the attempt fails with a *ResponseBodyTooLargeError. If the response body
+// is compressed, the limit applies to the decompressed body. By default, the response body size is unlimited.
+func WithMaxResponseBodySize(maxSize int64) ClientOpt {
+	if maxSize < 1 {
+		panic("maxSize must be >=1")
+	}
+
+	return func(client *Client) {
+		client.maxResponseBodySize = maxSize
+	}
+}
+
+// WithMaxRequestBytes configures a Client to reject requests whose marshaled request body is larger than maxSize
+// bytes with a *RequestTooLargeError, without sending them and without retrying. This guards against runaway
+// serialization. Multipart and raw request bodies are not checked, since they are streamed. If maxSize is 0,
+// the request body size is unlimited, which is the default.
+func WithMaxRequestBytes(maxSize int64) ClientOpt {
+	if maxSize < 0 {
+		panic("maxSize must be >=0")
+	}
+
+	return func(client *Client) {
+		client.maxRequestBodySize = maxSize
+	}
+}
+
+// checkRequestBodySize returns a *RequestTooLargeError if body exceeds the client's maximum request body size.
+func checkRequestBodySize(client *Client, body []byte) error {
+	if client.maxRequestBodySize <= 0 || int64(len(body)) <= client.maxRequestBodySize {
+		return nil
+	}
+
+	return &RequestTooLargeError{
+		Size:  int64(len(body)),
+		Limit: client.maxRequestBodySize,
+	}
+}
+
+// limitResponseBody returns a copy of httpRes whose body yields at most limit bytes.
+func limitResponseBody(httpRes *http.Response, limit int64) (*http.Response, *limitedBody) {
+	body := limitedBody{
+		body:  httpRes.Body,
+		limit: limit,
+	}
+
+	limitedRes := *httpRes
+	limitedRes.Body = &body
+
+	return &limitedRes, &body
+}
+
+// Read implements io.Reader.
+func (b *limitedBody) Read(buf []byte) (int, error) {
+	if b.exceeded {
+		return 0, &ResponseBodyTooLargeError{Limit: b.limit}
+	}
+
+	// read one byte more than allowed to detect whether the limit is exceeded
+	if remaining := b.limit - b.read + 1; int64(len(buf)) > remaining {
+		buf = buf[:remaining]
+	}
+
+	n, err := b.body.Read(buf)
+	b.read += int64(n)
+
+	if b.read > b.limit {
+		b.exceeded = true
+		return n - int(b.read-b.limit), &ResponseBodyTooLargeError{Limit: b.limit}
+	}
+
+	return n, err //nolint:wrapcheck // we don't add new info here
+}
+
+// Close implements io.Closer.
+func (b *limitedBody) Close() error {
+	return b.body.Close() //nolint:wrapcheck // we don't add new info here
+}
+
+// Error implements error.
+func (e *ResponseBodyTooLargeError) Error() string {
+	return "response body exceeds limit of " + strconv.FormatInt(e.Limit, 10) + " bytes"
+}
+
+// Error implements error.
+func (e *RequestTooLargeError) Error() string {
+	return "request body of " + strconv.FormatInt(e.Size, 10) + " bytes exceeds limit of " +
+		strconv.FormatInt(e.Limit, 10) + " bytes"
+}
@@ -0,0 +1,119 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	"github.com/matryer/is"
+)
+
+func TestDo_MaxResponseBodySize(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_ = json.MarshalWrite(writer, &testRes{Reply: strings.Repeat("x", 1000)})
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithMaxAttempts(1),
+		WithMaxResponseBodySize(100),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+
+	var tooLargeErr *ResponseBodyTooLargeError
+	is.True(errors.As(err, &tooLargeErr))
+	is.Equal(tooLargeErr.Limit, int64(100))
+}
+
+func TestDo_MaxResponseBodySize_ResponseError(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = writer.Write([]byte(strings.Repeat("x", 1<<20)))
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithMaxAttempts(1),
+		WithResponseErrors(),
+		WithMaxResponseBodySize(100),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+
+	var resErr *ResponseError
+	is.True(errors.As(err, &resErr))
+	is.Equal(resErr.StatusCode, http.StatusInternalServerError)
+	is.Equal(len(resErr.Body), 100)
+}
+
+func TestDo_MaxResponseBodySize_WithinLimit(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_ = json.MarshalWrite(writer, &testRes{Reply: "Hello, client!"})
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithMaxAttempts(1),
+		WithMaxResponseBodySize(int64(len(`{"reply":"Hello, client!"}`))),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+}
+
+func TestDo_MaxRequestBytes(t *testing.T) {
+	is := is.New(t)
+
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		requests++
+
+		_ = json.MarshalWrite(writer, &testRes{Reply: "Hi"})
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithMaxRequestBytes(100),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: strings.Repeat("x", 1000)})
+
+	_, err := Do(context.Background(), client, req)
+
+	var tooLargeErr *RequestTooLargeError
+	is.True(errors.As(err, &tooLargeErr))
+	is.Equal(tooLargeErr.Limit, int64(100))
+	is.Equal(tooLargeErr.Size, int64(len(`{"message":""}`)+1000))
+	is.Equal(requests, 0)
+
+	req = NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: "Hello"})
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hi")
+	is.Equal(requests, 1)
+}
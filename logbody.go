@@ -0,0 +1,131 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// errorReader is an io.Reader that always returns err.
+type errorReader struct {
+	err error
+}
+
+var _ io.Reader = errorReader{}
+
+// redactedValue replaces the values of redacted headers in logs.
+const redactedValue = "***"
+
+// WithLogBodies configures a Client to log the headers and body of the request and the response of each attempt
+// at debug level using the client's logger. Bodies longer than maxLength bytes are truncated in the log.
+// The response body is logged after decompression. Request bodies that are streamed, such as multipart bodies,
+// are not logged. The values of sensitive headers are redacted (see WithRedactedHeaders).
+//
+// This is useful to diagnose serialization mismatches, but may expose sensitive data in the logs.
+func WithLogBodies(maxLength int) ClientOpt {
+	if maxLength < 1 {
+		panic("maxLength must be >=1")
+	}
+
+	return func(client *Client) {
+		client.logBodiesMaxLength = maxLength
+	}
+}
+
+// WithRedactedHeaders configures a Client to redact the values of the headers with the given keys wherever headers
+// are logged (see WithLogBodies). Keys are case-insensitive. This replaces the default set of redacted headers,
+// which consists of Authorization, Cookie, and Set-Cookie.
+func WithRedactedHeaders(keys ...string) ClientOpt {
+	return func(client *Client) {
+		client.redactedHeaders = keys
+	}
+}
+
+// redactHeader returns a copy of header in which the values of the headers with the given keys have been replaced.
+func redactHeader(header http.Header, keys []string) http.Header {
+	redacted := header.Clone()
+
+	for _, key := range keys {
+		vals := redacted.Values(key)
+		if len(vals) == 0 {
+			continue
+		}
+
+		redactedVals := make([]string, len(vals))
+		for i := range redactedVals {
+			redactedVals[i] = redactedValue
+		}
+
+		redacted[http.CanonicalHeaderKey(key)] = redactedVals
+	}
+
+	return redacted
+}
+
+// logRequestBody logs the headers of httpReq, as well as its body if it can be obtained without consuming it.
+// Streamed bodies, whose length is unknown, are not obtained, since replaying them would rewind the shared reader.
+func logRequestBody(ctx context.Context, client *Client, httpReq *http.Request) {
+	var (
+		body      []byte
+		truncated bool
+	)
+
+	if httpReq.GetBody != nil && httpReq.ContentLength > 0 {
+		if reader, err := httpReq.GetBody(); err == nil {
+			body, truncated, _ = readLogBody(reader, client.logBodiesMaxLength)
+			_ = reader.Close()
+		}
+	}
+
+	if truncated {
+		body = body[:client.logBodiesMaxLength]
+	}
+
+	client.logger.DebugContext(ctx, "HTTP request body",
+		slog.Any("header", redactHeader(httpReq.Header, client.redactedHeaders)),
+		slog.String("body", string(body)),
+		slog.Bool("truncated", truncated),
+	)
+}
+
+// logResponseBody logs the headers and body of httpRes and returns a copy of httpRes whose body yields the full response
+// body again. If reading the response body fails, the copy's body returns the same error after the data read
+// so far, so that the error is reported when the response is decoded.
+func logResponseBody(ctx context.Context, client *Client, httpRes *http.Response) *http.Response {
+	body, truncated, err := readLogBody(httpRes.Body, client.logBodiesMaxLength)
+
+	var rest io.Reader = httpRes.Body
+	if err != nil {
+		rest = errorReader{err: err}
+	}
+
+	loggedRes := *httpRes
+	loggedRes.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), rest))
+
+	logBody := body
+	if truncated {
+		logBody = body[:client.logBodiesMaxLength]
+	}
+
+	client.logger.DebugContext(ctx, "HTTP response body",
+		slog.Int("status", httpRes.StatusCode),
+		slog.Any("header", redactHeader(httpRes.Header, client.redactedHeaders)),
+		slog.String("body", string(logBody)),
+		slog.Bool("truncated", truncated),
+	)
+
+	return &loggedRes
+}
+
+// readLogBody reads up to maxLength+1 bytes from reader, and returns whether the data is longer than maxLength.
+func readLogBody(reader io.Reader, maxLength int) ([]byte, bool, error) {
+	body, err := io.ReadAll(io.LimitReader(reader, int64(maxLength)+1))
+	return body, len(body) > maxLength, err //nolint:wrapcheck // we don't add new info here
+}
+
+// Read implements io.Reader.
+func (r errorReader) Read(_ []byte) (int, error) {
+	return 0, r.err
+}
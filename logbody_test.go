@@ -0,0 +1,148 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	"github.com/matryer/is"
+)
+
+func TestWithLogBodies(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"reply":"Hello, client!"}`))
+	}))
+
+	defer server.Close()
+
+	var logs bytes.Buffer
+
+	client := New(
+		WithLogger(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		WithLogBodies(12),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: "Hi"})
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+
+	var bodies []map[string]any
+
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		is.NoErr(json.Unmarshal([]byte(line), &entry))
+
+		if _, ok := entry["body"]; ok {
+			bodies = append(bodies, entry)
+		}
+	}
+
+	is.Equal(len(bodies), 2)
+
+	is.Equal(bodies[0]["msg"], "HTTP request body")
+	is.Equal(bodies[0]["body"], `{"message":"`)
+	is.Equal(bodies[0]["truncated"], true)
+
+	is.Equal(bodies[1]["msg"], "HTTP response body")
+	is.Equal(bodies[1]["body"], `{"reply":"He`)
+	is.Equal(bodies[1]["truncated"], true)
+}
+
+func TestWithLogBodies_Short(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"reply":"Hi"}`))
+	}))
+
+	defer server.Close()
+
+	var logs bytes.Buffer
+
+	client := New(
+		WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		WithLogBodies(1024),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil, WithSendBody[*testReq, *testRes](false))
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hi")
+	is.True(strings.Contains(logs.String(), `body="{\"reply\":\"Hi\"}" truncated=false`))
+}
+
+func TestWithRedactedHeaders(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.SetCookie(writer, &http.Cookie{Name: "session", Value: "secret-session"})
+		writer.Header().Set("X-Api-Key", "secret-response-key")
+		_, _ = writer.Write([]byte(`{"reply":"Hi"}`))
+	}))
+
+	defer server.Close()
+
+	var logs bytes.Buffer
+
+	client := New(
+		WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		WithLogBodies(1024),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil,
+		WithSendBody[*testReq, *testRes](false),
+		WithHeader[*testReq, *testRes]("Authorization", "Bearer secret-token"),
+	)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.True(!strings.Contains(logs.String(), "secret-token"))
+	is.True(!strings.Contains(logs.String(), "secret-session"))
+	is.True(strings.Contains(logs.String(), "secret-response-key"))
+	is.True(strings.Contains(logs.String(), "***"))
+
+	logs.Reset()
+
+	client = New(
+		WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		WithLogBodies(1024),
+		WithRedactedHeaders("x-api-key"),
+	)
+
+	_, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.True(!strings.Contains(logs.String(), "secret-response-key"))
+	is.True(strings.Contains(logs.String(), "secret-token"))
+}
+
+func TestRedactHeader(t *testing.T) {
+	is := is.New(t)
+
+	header := http.Header{
+		"Authorization": {"Bearer abc"},
+		"Cookie":        {"a=1", "b=2"},
+		"Accept":        {"application/json"},
+	}
+
+	redacted := redactHeader(header, []string{"authorization", "Cookie", "Set-Cookie"})
+
+	is.Equal(redacted, http.Header{
+		"Authorization": {"***"},
+		"Cookie":        {"***", "***"},
+		"Accept":        {"application/json"},
+	})
+
+	is.Equal(header.Get("Authorization"), "Bearer abc")
+}
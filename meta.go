@@ -0,0 +1,72 @@
+package gojsonclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Result is the decoded response data of a request made using DoWithMeta, together with metadata about the response.
+type Result[T any] struct {
+	// Res is the value decoded from the response body, in the same way as Response.Res.
+	Res T
+
+	// Meta contains metadata about the response.
+	Meta ResponseMeta
+}
+
+// ResponseMeta contains commonly needed metadata about a response.
+type ResponseMeta struct {
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+
+	// Header contains the HTTP response headers.
+	Header http.Header
+
+	// Duration is the time it took to send the request and receive the response headers in the attempt that
+	// produced the response.
+	Duration time.Duration
+
+	// Attempts is the number of attempts it took to produce the response.
+	Attempts int
+
+	// RequestID is the value of the X-Request-Id response header, if any.
+	RequestID string
+
+	// BodyBytes is the number of bytes of the response body that have been read, before decompression.
+	BodyBytes int64
+}
+
+// WithResponseMeta configures a Request so that Do populates Response.Meta with metadata about the response.
+// DoWithMeta always returns the metadata, regardless of this option.
+func WithResponseMeta[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.responseMeta = true
+	}
+}
+
+// DoWithMeta executes req with client in the same way as Do, but returns the decoded response data together
+// with metadata about the response, such as the request ID and the size of the response body.
+// If Do returns both a response and an error, DoWithMeta does the same.
+func DoWithMeta[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res]) (*Result[Res], error) {
+	res, err := Do(ctx, client, req)
+	if res == nil {
+		return nil, err
+	}
+
+	return &Result[Res]{
+		Res:  res.Res,
+		Meta: newResponseMeta(res),
+	}, err
+}
+
+func newResponseMeta[Res any](res *Response[Res]) ResponseMeta {
+	return ResponseMeta{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Duration:   res.Duration,
+		Attempts:   res.Attempts,
+		RequestID:  res.Header.Get("X-Request-Id"),
+		BodyBytes:  res.bodyBytes,
+	}
+}
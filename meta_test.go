@@ -0,0 +1,134 @@
+package gojsonclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDoWithMeta(t *testing.T) {
+	is := is.New(t)
+
+	body := `{"reply":"Hello, client!"}`
+
+	attempts := 0
+
+	clock := newFakeClock()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		clock.advance(10 * time.Millisecond)
+
+		writer.Header().Set("X-Request-Id", "abc123")
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = writer.Write([]byte(body))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(clock), WithResponseErrors())
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: "Hi"})
+
+	res, err := DoWithMeta(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(res.Res.Reply, "Hello, client!")
+	is.Equal(res.Meta.StatusCode, http.StatusCreated)
+	is.Equal(res.Meta.Header.Get("X-Request-Id"), "abc123")
+	is.Equal(res.Meta.RequestID, "abc123")
+	is.Equal(res.Meta.Attempts, 2)
+	is.Equal(res.Meta.Duration, 10*time.Millisecond)
+	is.Equal(res.Meta.BodyBytes, int64(len(body)))
+}
+
+func TestDoWithMeta_Error(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()), WithResponseErrors(), WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	res, err := DoWithMeta(context.Background(), client, req)
+	is.True(err != nil)
+	is.Equal(res, nil)
+}
+
+func TestWithResponseMeta(t *testing.T) {
+	is := is.New(t)
+
+	body := `{"reply":"Hello, client!"}`
+
+	attempts := 0
+
+	clock := newFakeClock()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		clock.advance(10 * time.Millisecond)
+
+		writer.Header().Set("X-Request-Id", "abc123")
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = writer.Write([]byte(body))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(clock), WithResponseErrors())
+
+	req := NewRequest(server.URL, http.MethodPost, &testReq{Message: "Hi"},
+		WithResponseMeta[*testReq, *testRes](),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(res.Res.Reply, "Hello, client!")
+	is.True(res.Meta != nil)
+	is.Equal(res.Meta.StatusCode, http.StatusCreated)
+	is.Equal(res.Meta.Header.Get("X-Request-Id"), "abc123")
+	is.Equal(res.Meta.RequestID, "abc123")
+	is.Equal(res.Meta.Attempts, 2)
+	is.Equal(res.Meta.Duration, 10*time.Millisecond)
+	is.Equal(res.Meta.BodyBytes, int64(len(body)))
+}
+
+func TestWithResponseMeta_NotConfigured(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"reply":"Hello, client!"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Meta, nil)
+}
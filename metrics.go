@@ -0,0 +1,68 @@
+package gojsonclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricsHook is notified about HTTP requests made by a Client. It can be used to record metrics such as
+// request counts, failures, and latencies without depending on a specific metrics library.
+//
+// uri is the request URI including the client's base URI, but without any query parameters added
+// using WithQueryParams, so that it may be used to identify an endpoint.
+type MetricsHook interface {
+	// RequestStarted is called before an HTTP request is executed.
+	RequestStarted(method string, uri string)
+
+	// RequestCompleted is called after an HTTP request has been executed, before the response body is decoded.
+	// statusCode is 0 if no response was received.
+	RequestCompleted(method string, uri string, statusCode int, duration time.Duration, err error)
+}
+
+// RequestSizeFunc is a function that is notified about the size of a request body.
+type RequestSizeFunc func(method string, url string, size int)
+
+// WithMetrics configures a Client to notify hook about all HTTP requests made, including retries.
+func WithMetrics(hook MetricsHook) ClientOpt {
+	return func(client *Client) {
+		client.metrics = hook
+	}
+}
+
+// WithOnRequestSize configures a Client to call fun with the size in bytes of the marshaled request body before
+// each attempt to execute a request, for example to alert about large payloads. url includes any query parameters.
+// fun is not called for multipart and raw request bodies (see WithRawRequestBody), since they are streamed and their
+// size is not known in advance.
+// Requests made using DoDuplex are reported with a size of 0, since their request body is streamed separately.
+// Any number of functions may be added. They are called in the order they were added.
+func WithOnRequestSize(fun RequestSizeFunc) ClientOpt {
+	if fun == nil {
+		panic("fun must not be nil")
+	}
+
+	return func(client *Client) {
+		client.onRequestSize = append(client.onRequestSize, fun)
+	}
+}
+
+// executeHTTPRequest executes httpReq using the client's HTTP client and notifies the client's metrics hook, if any.
+func executeHTTPRequest(client *Client, httpReq *http.Request, method string, uri string) (*http.Response, error) {
+	if client.metrics == nil {
+		return client.httpClient.Do(httpReq) //nolint:wrapcheck // we don't add new info here
+	}
+
+	client.metrics.RequestStarted(method, uri)
+
+	start := client.clock.Now()
+
+	httpRes, err := client.httpClient.Do(httpReq)
+
+	statusCode := 0
+	if httpRes != nil {
+		statusCode = httpRes.StatusCode
+	}
+
+	client.metrics.RequestCompleted(method, uri, statusCode, client.clock.Now().Sub(start), err)
+
+	return httpRes, err //nolint:wrapcheck // we don't add new info here
+}
@@ -0,0 +1,104 @@
+package gojsonclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+type testMetricsHook struct {
+	started   []string
+	completed []int
+}
+
+var _ MetricsHook = (*testMetricsHook)(nil)
+
+func TestDo_Metrics(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	hook := testMetricsHook{}
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithBaseURI(server.URL),
+		WithMetrics(&hook),
+	)
+
+	req := NewRequest[*testReq, *testRes]("/foo", http.MethodPost, nil)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(hook.started, []string{"POST " + server.URL + "/foo", "POST " + server.URL + "/foo"})
+	is.Equal(hook.completed, []int{http.StatusInternalServerError, http.StatusNoContent})
+}
+
+func (h *testMetricsHook) RequestStarted(method string, uri string) {
+	h.started = append(h.started, method+" "+uri)
+}
+
+func (h *testMetricsHook) RequestCompleted(_ string, _ string, statusCode int, _ time.Duration, _ error) {
+	h.completed = append(h.completed, statusCode)
+}
+
+func TestWithOnRequestSize(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"reply":"Hi"}`))
+	}))
+
+	defer server.Close()
+
+	type sizeInfo struct {
+		method string
+		uri    string
+		size   int
+	}
+
+	var sizes []sizeInfo
+
+	client := New(
+		WithOnRequestSize(func(method string, uri string, size int) {
+			sizes = append(sizes, sizeInfo{method, uri, size})
+		}),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: "Hello"},
+		WithQueryParams[*testReq, *testRes](url.Values{"a": {"1"}}),
+	)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(sizes, []sizeInfo{{http.MethodPost, server.URL + "?a=1", len(`{"message":"Hello"}`)}})
+
+	sizes = nil
+
+	req = NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil, WithSendBody[*testReq, *testRes](false))
+
+	_, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(sizes, []sizeInfo{{http.MethodGet, server.URL, 0}})
+}
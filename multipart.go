@@ -0,0 +1,177 @@
+package gojsonclient
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// Part is a part of a multipart/form-data request body.
+type Part struct {
+	// Name is the form field name.
+	Name string
+
+	// FileName is the file name. If empty, the part is sent as a regular form field.
+	FileName string
+
+	// ContentType is the content type of the part. If empty, no Content-Type header is sent for regular
+	// form fields, and application/octet-stream is used for files.
+	ContentType string
+
+	// Reader provides the content of the part. It is ignored if Open is set.
+	Reader io.Reader
+
+	// Open returns a new reader that provides the content of the part. If set, it is called for each attempt,
+	// and each time the HTTP client replays the request body, and the reader returned is closed afterwards.
+	Open func() (io.ReadCloser, error)
+}
+
+// multipartBody is a multipart/form-data request body that is encoded while it is being read.
+type multipartBody struct {
+	parts  []Part
+	reader *io.PipeReader
+	writer *io.PipeWriter
+	mw     *multipart.Writer
+	once   sync.Once
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// WithMultipartBody configures a Request to send parts as a multipart/form-data request body instead of
+// the request data, and to set the Content-Type header accordingly. The parts are streamed to the server
+// while the request is sent, rather than buffered in memory.
+//
+// If the request is retried, Part.Open is called again, or the reader of each part that implements io.Seeker
+// is rewound to the start. Other readers can only be read once, so a request using them should not be retried
+// (see WithMaxAttempts). If all parts use Part.Open or readers that implement io.Seeker, the HTTP client may also
+// replay the request body itself, for example when following redirects.
+//
+// Since the readers of parts that do not use Part.Open are shared by all attempts, a Request using them must not
+// be executed concurrently. Parts that use Part.Open get a new reader for each attempt, so they can be.
+func WithMultipartBody[Req any, Res any](parts []Part) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.multipartParts = parts
+	}
+}
+
+func newMultipartBody(parts []Part) (*multipartBody, error) {
+	for _, part := range parts {
+		seeker, ok := part.Reader.(io.Seeker)
+		if !ok || part.Open != nil {
+			continue
+		}
+
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("rewind part %q: %w", part.Name, err)
+		}
+	}
+
+	reader, writer := io.Pipe()
+
+	return &multipartBody{
+		parts:  parts,
+		reader: reader,
+		writer: writer,
+		mw:     multipart.NewWriter(writer),
+	}, nil
+}
+
+// getBody returns a function that returns a new multipartBody that encodes the same parts using the same boundary,
+// so that the request body can be replayed by the HTTP client. It returns nil if any part does not use Part.Open,
+// and its reader does not implement io.Seeker.
+func (b *multipartBody) getBody() func() (io.ReadCloser, error) {
+	for _, part := range b.parts {
+		if _, ok := part.Reader.(io.Seeker); !ok && part.Open == nil {
+			return nil
+		}
+	}
+
+	return func() (io.ReadCloser, error) {
+		body, err := newMultipartBody(b.parts)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = body.mw.SetBoundary(b.mw.Boundary()); err != nil {
+			return nil, fmt.Errorf("set boundary: %w", err)
+		}
+
+		return body, nil
+	}
+}
+
+func (b *multipartBody) contentType() string {
+	return b.mw.FormDataContentType()
+}
+
+// Read implements io.Reader. The parts are encoded in a separate goroutine that is started on the first call.
+func (b *multipartBody) Read(buf []byte) (int, error) {
+	b.once.Do(func() {
+		go func() {
+			_ = b.writer.CloseWithError(b.writeParts())
+		}()
+	})
+
+	return b.reader.Read(buf) //nolint:wrapcheck // we don't add new info here
+}
+
+// Close implements io.Closer.
+func (b *multipartBody) Close() error {
+	return b.reader.Close() //nolint:wrapcheck // we don't add new info here
+}
+
+func (b *multipartBody) writeParts() error {
+	for _, part := range b.parts {
+		if err := b.writePart(part); err != nil {
+			return err
+		}
+	}
+
+	return b.mw.Close() //nolint:wrapcheck // we don't add new info here
+}
+
+func (b *multipartBody) writePart(part Part) error {
+	reader := part.Reader
+
+	if part.Open != nil {
+		readCloser, err := part.Open()
+		if err != nil {
+			return fmt.Errorf("open part %q: %w", part.Name, err)
+		}
+
+		defer readCloser.Close() //nolint:errcheck // we're only reading
+
+		reader = readCloser
+	}
+
+	header := textproto.MIMEHeader{}
+
+	disposition := `form-data; name="` + quoteEscaper.Replace(part.Name) + `"`
+	if part.FileName != "" {
+		disposition += `; filename="` + quoteEscaper.Replace(part.FileName) + `"`
+	}
+
+	header.Set("Content-Disposition", disposition)
+
+	switch {
+	case part.ContentType != "":
+		header.Set("Content-Type", part.ContentType)
+
+	case part.FileName != "":
+		header.Set("Content-Type", "application/octet-stream")
+	}
+
+	writer, err := b.mw.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("create part %q: %w", part.Name, err)
+	}
+
+	if _, err = io.Copy(writer, reader); err != nil {
+		return fmt.Errorf("write part %q: %w", part.Name, err)
+	}
+
+	return nil
+}
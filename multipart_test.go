@@ -0,0 +1,193 @@
+package gojsonclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDo_MultipartBody(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		attempts++
+
+		is.True(strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/form-data; boundary="))
+
+		reader, err := req.MultipartReader()
+		is.NoErr(err)
+
+		part, err := reader.NextPart()
+		is.NoErr(err)
+		is.Equal(part.FormName(), "title")
+		is.Equal(part.FileName(), "")
+
+		data, _ := io.ReadAll(part)
+		is.Equal(string(data), "Report")
+
+		part, err = reader.NextPart()
+		is.NoErr(err)
+		is.Equal(part.FormName(), "file")
+		is.Equal(part.FileName(), "report.csv")
+		is.Equal(part.Header.Get("Content-Type"), "text/csv")
+
+		data, _ = io.ReadAll(part)
+		is.Equal(string(data), "a,b\n1,2\n")
+
+		_, err = reader.NextPart()
+		is.Equal(err, io.EOF)
+
+		if attempts == 1 {
+			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, nil,
+		WithMultipartBody[*testReq, *testRes]([]Part{
+			{
+				Name:   "title",
+				Reader: strings.NewReader("Report"),
+			},
+			{
+				Name:        "file",
+				FileName:    "report.csv",
+				ContentType: "text/csv",
+				Reader:      strings.NewReader("a,b\n1,2\n"),
+			},
+		}),
+	)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(attempts, 2)
+}
+
+func TestDo_MultipartBody_Redirect(t *testing.T) {
+	is := is.New(t)
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/start", func(writer http.ResponseWriter, req *http.Request) {
+		_, _ = io.Copy(io.Discard, req.Body)
+		http.Redirect(writer, req, "/final", http.StatusTemporaryRedirect)
+	})
+
+	mux.HandleFunc("/final", func(writer http.ResponseWriter, req *http.Request) {
+		is.NoErr(req.ParseMultipartForm(1024))
+		is.Equal(req.FormValue("title"), "Report")
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := New(WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL+"/start", http.MethodPost, nil,
+		WithMultipartBody[*testReq, *testRes]([]Part{
+			{
+				Name:   "title",
+				Reader: strings.NewReader("Report"),
+			},
+		}),
+	)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+}
+
+func TestDo_MultipartBody_Open_Concurrent(t *testing.T) {
+	is := is.New(t)
+
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		reader, err := req.MultipartReader()
+		is.NoErr(err)
+
+		part, err := reader.NextPart()
+		is.NoErr(err)
+		is.Equal(part.FormName(), "file")
+
+		data, _ := io.ReadAll(part)
+		is.Equal(string(data), "a,b\n1,2\n")
+
+		// fail the first attempts, so that requests are retried
+		if attempts.Add(1) <= 10 {
+			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	var opened, closed atomic.Int32
+
+	client := New(WithClock(newFakeClock()), WithMaxAttempts(20))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, nil,
+		WithMultipartBody[*testReq, *testRes]([]Part{
+			{
+				Name:     "file",
+				FileName: "report.csv",
+
+				Open: func() (io.ReadCloser, error) {
+					opened.Add(1)
+
+					return &closeCountingReader{
+						Reader: strings.NewReader("a,b\n1,2\n"),
+						closed: &closed,
+					}, nil
+				},
+			},
+		}),
+	)
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := Do(context.Background(), client, req)
+			is.NoErr(err)
+		}()
+	}
+
+	wg.Wait()
+
+	is.Equal(opened.Load(), attempts.Load())
+	is.Equal(closed.Load(), opened.Load())
+}
+
+type closeCountingReader struct {
+	io.Reader
+	closed *atomic.Int32
+}
+
+func (r *closeCountingReader) Close() error {
+	r.closed.Add(1)
+	return nil
+}
@@ -0,0 +1,75 @@
+package gojsonclient
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// UnexpectedContentTypeError is returned when the response to a request has a status code outside the 2xx range
+// and a Content-Type that is not JSON, and the Client has been configured using WithRejectNonJSONErrors.
+// It is also returned by DoSSE if the response is not a Server-Sent Events stream.
+type UnexpectedContentTypeError struct {
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+
+	// Status is the HTTP response status.
+	Status string
+
+	// ContentType is the value of the Content-Type header of the response.
+	ContentType string
+
+	// Body is the response body.
+	Body []byte
+}
+
+var _ error = (*UnexpectedContentTypeError)(nil)
+
+// WithRejectNonJSONErrors configures a Client to not decode response bodies whose Content-Type is not JSON
+// if the response status code is outside the 2xx range, for example HTML error pages returned by proxies.
+// Instead, the attempt fails with an *UnexpectedContentTypeError that contains the response body.
+// Content types are considered JSON if they are application/json or end in +json.
+func WithRejectNonJSONErrors() ClientOpt {
+	return func(client *Client) {
+		client.rejectNonJSONErrors = true
+	}
+}
+
+// checkErrorContentType returns an *UnexpectedContentTypeError if httpRes has a status code outside the 2xx range
+// and a Content-Type that is not JSON.
+func checkErrorContentType(httpRes *http.Response) error {
+	contentType := httpRes.Header.Get("Content-Type")
+
+	if isSuccess(httpRes.StatusCode) || isJSONContentType(contentType) {
+		return nil
+	}
+
+	body, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+
+	return &UnexpectedContentTypeError{
+		StatusCode:  httpRes.StatusCode,
+		Status:      httpRes.Status,
+		ContentType: contentType,
+		Body:        body,
+	}
+}
+
+// isJSONContentType returns true if contentType is application/json or ends in +json.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Error implements error.
+func (e *UnexpectedContentTypeError) Error() string {
+	return "unexpected content type " + e.ContentType + " of response with status " + e.Status
+}
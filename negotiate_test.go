@@ -0,0 +1,79 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithRejectNonJSONErrors(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		writer.WriteHeader(http.StatusBadGateway)
+		_, _ = writer.Write([]byte("<html>Bad Gateway</html>"))
+	}))
+
+	defer server.Close()
+
+	client := New(WithMaxAttempts(1), WithUnwrapFinalError(), WithRejectNonJSONErrors())
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+
+	var contentTypeErr *UnexpectedContentTypeError
+	is.True(errors.As(err, &contentTypeErr))
+	is.Equal(contentTypeErr.StatusCode, http.StatusBadGateway)
+	is.Equal(contentTypeErr.ContentType, "text/html; charset=utf-8")
+	is.Equal(string(contentTypeErr.Body), "<html>Bad Gateway</html>")
+
+	var decodeErr *DecodeError
+	is.True(!errors.As(err, &decodeErr))
+}
+
+func TestWithRejectNonJSONErrors_JSON(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/problem+json")
+		writer.WriteHeader(http.StatusNotFound)
+		_, _ = writer.Write([]byte(`{"reply":"not found"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithMaxAttempts(1), WithRejectNonJSONErrors())
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "not found")
+}
+
+func TestIsJSONContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"application/problem+json", true},
+		{"text/html", false},
+		{"text/plain; charset=utf-8", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.contentType, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(isJSONContentType(test.contentType), test.want)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// DoPagesConcurrent fetches the pages 1 through totalPages of a paginated list, for APIs where the total number
+// of pages is known in advance, for example from a response header or the first page. It creates a Request for
+// each page using makeReq and executes the requests using Do, running at most concurrency requests at the same time.
+// Each request is retried independently. It returns the items of all pages concatenated in page order.
+//
+// If a page fails, no further pages are started, and requests in flight are canceled. DoPagesConcurrent then
+// returns the items of the pages preceding the first failed page, so that the result is always a contiguous prefix
+// of the list, together with the errors of all failed pages joined together. If ctx is canceled before all pages
+// have been fetched, the error returned is or wraps ctx.Err().
+func DoPagesConcurrent[Req any, Item any](ctx context.Context, client *Client, makeReq func(page int) *Request[Req, []Item],
+	totalPages int, concurrency int,
+) ([]Item, error) {
+	if totalPages < 0 {
+		panic("totalPages must be >=0")
+	}
+
+	if concurrency < 1 {
+		panic("concurrency must be >=1")
+	}
+
+	parentCtx := ctx
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pageItems := make([][]Item, totalPages)
+	pageDone := make([]bool, totalPages)
+	pageErrs := make([]error, totalPages)
+
+	pages := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range min(concurrency, totalPages) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for page := range pages {
+				res, err := Do(ctx, client, makeReq(page))
+				if err != nil {
+					// requests canceled because of another page's failure are not reported
+					if !errors.Is(err, context.Canceled) || parentCtx.Err() != nil {
+						pageErrs[page-1] = fmt.Errorf("page %d: %w", page, err)
+					}
+
+					cancel()
+
+					continue
+				}
+
+				pageItems[page-1], pageDone[page-1] = res.Res, true
+			}
+		}()
+	}
+
+	for page := 1; page <= totalPages && ctx.Err() == nil; page++ {
+		select {
+		case pages <- page:
+		case <-ctx.Done():
+		}
+	}
+
+	close(pages)
+	wg.Wait()
+
+	items := collectPages(pageItems, pageDone)
+
+	err := errors.Join(pageErrs...)
+
+	// pages that were never started because the parent context has been canceled do not have errors
+	if err == nil && slices.Contains(pageDone, false) {
+		err = parentCtx.Err()
+	}
+
+	return items, err
+}
+
+// collectPages returns the items of the pages preceding the first page that has not been fetched successfully.
+func collectPages[Item any](pageItems [][]Item, pageDone []bool) []Item {
+	var items []Item
+
+	for page, done := range pageDone {
+		if !done {
+			break
+		}
+
+		items = append(items, pageItems[page]...)
+	}
+
+	return items
+}
@@ -0,0 +1,123 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/matryer/is"
+)
+
+func TestDoPagesConcurrent(t *testing.T) {
+	is := is.New(t)
+
+	var inFlight, maxInFlight atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			prev := maxInFlight.Load()
+			if current <= prev || maxInFlight.CompareAndSwap(prev, current) {
+				break
+			}
+		}
+
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+
+		// later pages respond faster, so that they complete out of order
+		time.Sleep(time.Duration(5-page) * 5 * time.Millisecond)
+
+		_ = json.MarshalWrite(writer, []string{"p" + strconv.Itoa(page) + "a", "p" + strconv.Itoa(page) + "b"})
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	items, err := DoPagesConcurrent(context.Background(), client, func(page int) *Request[any, []string] {
+		return NewRequest[any, []string](server.URL+"?page="+strconv.Itoa(page), http.MethodGet, nil)
+	}, 5, 3)
+
+	is.NoErr(err)
+	is.Equal(items, []string{"p1a", "p1b", "p2a", "p2b", "p3a", "p3b", "p4a", "p4b", "p5a", "p5b"})
+	is.True(maxInFlight.Load() <= 3)
+}
+
+func TestDoPagesConcurrent_Error(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+
+		if page == 3 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_ = json.MarshalWrite(writer, []int{page})
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()), WithResponseErrors(), WithMaxAttempts(1))
+
+	items, err := DoPagesConcurrent(context.Background(), client, func(page int) *Request[any, []int] {
+		return NewRequest[any, []int](server.URL+"?page="+strconv.Itoa(page), http.MethodGet, nil)
+	}, 5, 1)
+
+	var resErr *ResponseError
+	is.True(errors.As(err, &resErr))
+	is.Equal(resErr.StatusCode, http.StatusServiceUnavailable)
+	is.Equal(err.Error()[:7], "page 3:")
+	is.Equal(items, []int{1, 2})
+}
+
+func TestDoPagesConcurrent_Canceled(t *testing.T) {
+	is := is.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+
+		if page == 2 {
+			cancel()
+		}
+
+		_ = json.MarshalWrite(writer, []int{page})
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()), WithMaxAttempts(1))
+
+	items, err := DoPagesConcurrent(ctx, client, func(page int) *Request[any, []int] {
+		return NewRequest[any, []int](server.URL+"?page="+strconv.Itoa(page), http.MethodGet, nil)
+	}, 5, 1)
+
+	is.True(errors.Is(err, context.Canceled))
+	is.True(len(items) < 5)
+}
+
+func TestDoPagesConcurrent_CanceledBeforeStart(t *testing.T) {
+	is := is.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	items, err := DoPagesConcurrent(ctx, New(), func(page int) *Request[any, []int] {
+		return NewRequest[any, []int]("http://127.0.0.1:0?page="+strconv.Itoa(page), http.MethodGet, nil)
+	}, 5, 2)
+
+	is.True(errors.Is(err, context.Canceled))
+	is.Equal(len(items), 0)
+}
@@ -0,0 +1,113 @@
+package gojsonclient
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// RateLimitError is returned when the server responds with http.StatusTooManyRequests and the Request
+// has been configured using WithRateLimitError.
+type RateLimitError struct {
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+
+	// Status is the HTTP response status.
+	Status string
+
+	// RetryAfter is the delay requested by the server before making another attempt, or 0 if unknown.
+	RetryAfter time.Duration
+
+	// Reset is the time at which the rate limit resets, or the zero time if unknown.
+	Reset time.Time
+
+	// Body is the decoded response body, or nil if the body could not be decoded.
+	Body map[string]any
+}
+
+var _ error = (*RateLimitError)(nil)
+
+// WithRateLimitError configures a Request to decode the response body of an http.StatusTooManyRequests
+// response and return it as a *RateLimitError. The request is still retried, but a new attempt is delayed
+// until the time requested by the server has passed.
+//
+// The delay is taken from the Retry-After header (in seconds or as an HTTP date) if present, otherwise
+// from the "retry_after" field (in seconds) of the response body. The reset time is taken from the
+// X-RateLimit-Reset header (as a Unix timestamp) if present, otherwise from the "reset" field of the
+// response body.
+//
+// The delay is limited to the maximum delay configured using WithRespectRetryAfter, or to 1 minute if the Client
+// has not been configured using WithRespectRetryAfter.
+func WithRateLimitError[Req any, Res any]() RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.rateLimitError = true
+	}
+}
+
+// newRateLimitError returns a *RateLimitError for httpRes. HTTP dates in the Retry-After header are relative to now.
+func newRateLimitError(httpRes *http.Response, now time.Time) *RateLimitError {
+	err := RateLimitError{
+		StatusCode: httpRes.StatusCode,
+		Status:     httpRes.Status,
+	}
+
+	var body map[string]any
+	if json.UnmarshalRead(httpRes.Body, &body) == nil {
+		err.Body = body
+	}
+
+	if retryAfter, ok := parseRetryAfter(httpRes.Header.Get("Retry-After"), now); ok {
+		err.RetryAfter = retryAfter
+	} else if secs, ok := body["retry_after"].(float64); ok && secs > 0 {
+		err.RetryAfter = time.Duration(secs * float64(time.Second))
+	}
+
+	if secs, e := strconv.ParseInt(httpRes.Header.Get("X-RateLimit-Reset"), 10, 64); e == nil {
+		err.Reset = time.Unix(secs, 0)
+	} else if secs, ok := body["reset"].(float64); ok {
+		err.Reset = time.Unix(int64(secs), 0)
+	}
+
+	return &err
+}
+
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(secs)*time.Second, 0), true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// delay returns the time to wait before making another attempt, as of now.
+func (e *RateLimitError) delay(now time.Time) time.Duration {
+	if e.RetryAfter > 0 {
+		return e.RetryAfter
+	}
+
+	if !e.Reset.IsZero() {
+		return max(e.Reset.Sub(now), 0)
+	}
+
+	return 0
+}
+
+// Error implements error.
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited: %s (retry after %s)", e.Status, e.RetryAfter)
+	}
+
+	return "rate limited: " + e.Status
+}
@@ -0,0 +1,165 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDo_RateLimitError(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Retry-After", "120")
+		writer.Header().Set("X-RateLimit-Reset", "1700000000")
+		writer.WriteHeader(http.StatusTooManyRequests)
+		_, _ = writer.Write([]byte(`{"message":"slow down"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithMaxAttempts(1),
+	)
+
+	req := NewRequest(server.URL, http.MethodGet, (*testReq)(nil),
+		WithRateLimitError[*testReq, *testRes](),
+	)
+
+	_, err := Do(context.Background(), client, req)
+
+	var rateLimitErr *RateLimitError
+	is.True(errors.As(err, &rateLimitErr))
+	is.Equal(rateLimitErr.StatusCode, http.StatusTooManyRequests)
+	is.Equal(rateLimitErr.RetryAfter, 120*time.Second)
+	is.True(rateLimitErr.Reset.Equal(time.Unix(1700000000, 0)))
+	is.Equal(rateLimitErr.Body["message"], "slow down")
+}
+
+func TestDo_RateLimitError_Delay(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			writer.WriteHeader(http.StatusTooManyRequests)
+			_, _ = writer.Write([]byte(`{"retry_after":5}`))
+
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	clock := newFakeClock()
+
+	client := New(
+		WithClock(clock),
+	)
+
+	req := NewRequest(server.URL, http.MethodGet, (*testReq)(nil),
+		WithRateLimitError[*testReq, *testRes](),
+	)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(attempts, 2)
+	is.Equal(clock.sleeps, []time.Duration{5 * time.Second})
+}
+
+func TestDo_RateLimitError_RetryAfterDate_Clock(t *testing.T) {
+	is := is.New(t)
+
+	clock := newFakeClock()
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			writer.Header().Set("Retry-After", clock.Now().Add(45*time.Second).Format(http.TimeFormat))
+			writer.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(clock),
+	)
+
+	req := NewRequest(server.URL, http.MethodGet, (*testReq)(nil),
+		WithRateLimitError[*testReq, *testRes](),
+	)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(attempts, 2)
+	is.Equal(clock.sleeps, []time.Duration{45 * time.Second})
+}
+
+func TestDo_RateLimitError_MaxDelay(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []ClientOpt
+		sleep time.Duration
+	}{
+		{"default", nil, time.Minute},
+		{"respect retry after", []ClientOpt{WithRespectRetryAfter(20 * time.Second)}, 20 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			is := is.New(t)
+
+			attempts := 0
+
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				attempts++
+
+				if attempts == 1 {
+					writer.Header().Set("Retry-After", "86400")
+					writer.WriteHeader(http.StatusTooManyRequests)
+
+					return
+				}
+
+				http.Error(writer, "No Content", http.StatusNoContent)
+			}))
+
+			defer server.Close()
+
+			clock := newFakeClock()
+
+			client := New(append(test.opts, WithClock(clock))...)
+
+			req := NewRequest(server.URL, http.MethodGet, (*testReq)(nil),
+				WithRateLimitError[*testReq, *testRes](),
+			)
+
+			_, err := Do(context.Background(), client, req)
+			is.NoErr(err)
+
+			is.Equal(attempts, 2)
+			is.Equal(clock.sleeps, []time.Duration{test.sleep})
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package gojsonclient
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithRawRequestBody configures a Request to send the data read from reader as the request body instead of
+// the request data, for example a JSON document that has already been serialized. The request data is not
+// marshaled. The Content-Type header is set to contentType, or to the request's or client's default if it is empty.
+//
+// If the request is retried, reader is rewound to the start if it implements io.Seeker. Other readers can only
+// be read once, so a request using them should not be retried (see WithMaxAttempts). If reader implements
+// io.Seeker, the HTTP client may also replay the request body itself, for example when following redirects.
+// If reader is a *bytes.Reader, *bytes.Buffer, or *strings.Reader, its length is sent in the Content-Length header.
+//
+// Since reader is shared by all attempts, a Request configured using WithRawRequestBody must not be executed
+// concurrently. Use WithRawRequestBodyFunc instead to send the same Request concurrently.
+func WithRawRequestBody[Req any, Res any](reader io.Reader, contentType string) RequestOpt[Req, Res] {
+	if reader == nil {
+		panic("reader must not be nil")
+	}
+
+	return func(req *Request[Req, Res]) {
+		req.rawBody = reader
+		req.rawBodyOpen = nil
+		req.rawBodyContentType = contentType
+	}
+}
+
+// WithRawRequestBodyFunc configures a Request in the same way as WithRawRequestBody, but calls open to get a new
+// reader for each attempt, and each time the HTTP client replays the request body. The reader returned is closed
+// after the request has been sent. Since the reader is not shared by attempts, the Request may be retried and
+// executed concurrently. The length of the request body is not sent in the Content-Length header.
+func WithRawRequestBodyFunc[Req any, Res any](open func() (io.ReadCloser, error), contentType string) RequestOpt[Req, Res] {
+	if open == nil {
+		panic("open must not be nil")
+	}
+
+	return func(req *Request[Req, Res]) {
+		req.rawBody = nil
+		req.rawBodyOpen = open
+		req.rawBodyContentType = contentType
+	}
+}
+
+// rawRequestBody rewinds reader to the start if it implements io.Seeker, and returns it.
+func rawRequestBody(reader io.Reader) (io.Reader, error) {
+	if seeker, ok := reader.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("rewind raw body: %w", err)
+		}
+	}
+
+	return reader, nil
+}
+
+// rawGetBody returns a function that rewinds reader to the start and returns it, so that the request body can be
+// replayed by the HTTP client. It returns nil if reader does not implement io.Seeker.
+func rawGetBody(reader io.Reader) func() (io.ReadCloser, error) {
+	if _, ok := reader.(io.Seeker); !ok {
+		return nil
+	}
+
+	return func() (io.ReadCloser, error) {
+		body, err := rawRequestBody(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return io.NopCloser(body), nil
+	}
+}
+
+// rawOpenGetBody returns a function that returns a new reader using open, so that the request body can be
+// replayed by the HTTP client.
+func rawOpenGetBody(open func() (io.ReadCloser, error)) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		body, err := open()
+		if err != nil {
+			return nil, fmt.Errorf("open raw body: %w", err)
+		}
+
+		return body, nil
+	}
+}
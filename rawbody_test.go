@@ -0,0 +1,181 @@
+package gojsonclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithRawRequestBody(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		attempts++
+
+		body, err := io.ReadAll(req.Body)
+		is.NoErr(err)
+		is.Equal(string(body), `{"message":"cached"}`)
+		is.Equal(req.Header.Get("Content-Type"), "application/vnd.test+json")
+		is.Equal(req.ContentLength, int64(len(body)))
+
+		if attempts == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = writer.Write([]byte(`{"reply":"Hi"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()), WithResponseErrors())
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: "ignored"},
+		WithRawRequestBody[*testReq, *testRes](strings.NewReader(`{"message":"cached"}`), "application/vnd.test+json"),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hi")
+	is.Equal(attempts, 2)
+}
+
+func TestWithRawRequestBody_DefaultContentType(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest[*testReq, *testRes]("http://example.com", http.MethodPost, nil,
+		WithRawRequestBody[*testReq, *testRes](strings.NewReader(`{}`), ""),
+	)
+
+	httpReq, err := newHTTPRequest(context.Background(), New(), req)
+	is.NoErr(err)
+	is.Equal(httpReq.Header.Get("Content-Type"), "application/json; charset=UTF-8")
+}
+
+// seekReader hides the concrete type of its reader from http.NewRequest.
+type seekReader struct {
+	io.ReadSeeker
+}
+
+func TestWithRawRequestBody_Redirect(t *testing.T) {
+	is := is.New(t)
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/start", func(writer http.ResponseWriter, req *http.Request) {
+		_, _ = io.Copy(io.Discard, req.Body)
+		http.Redirect(writer, req, "/final", http.StatusTemporaryRedirect)
+	})
+
+	mux.HandleFunc("/final", func(writer http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		is.NoErr(err)
+		is.Equal(string(body), `{"message":"replayed"}`)
+
+		_, _ = writer.Write([]byte(`{"reply":"Hi"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := New(WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL+"/start", http.MethodPost, nil,
+		WithRawRequestBody[*testReq, *testRes](seekReader{strings.NewReader(`{"message":"replayed"}`)}, ""),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hi")
+}
+
+func TestNewHTTPRequest_GetBody(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest[*testReq, *testRes]("http://example.com", http.MethodPost, &testReq{Message: "Hi"})
+
+	httpReq, err := newHTTPRequest(context.Background(), New(), req)
+	is.NoErr(err)
+
+	_, _ = io.Copy(io.Discard, httpReq.Body)
+
+	body, err := httpReq.GetBody()
+	is.NoErr(err)
+
+	data, _ := io.ReadAll(body)
+	is.Equal(string(data), `{"message":"Hi"}`)
+
+	req = NewRequest[*testReq, *testRes]("http://example.com", http.MethodPost, nil,
+		WithRawRequestBody[*testReq, *testRes](struct{ io.Reader }{strings.NewReader("x")}, ""),
+	)
+
+	httpReq, err = newHTTPRequest(context.Background(), New(), req)
+	is.NoErr(err)
+	is.Equal(httpReq.GetBody, nil)
+}
+
+func TestWithRawRequestBodyFunc_Concurrent(t *testing.T) {
+	is := is.New(t)
+
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		is.NoErr(err)
+		is.Equal(string(body), `{"message":"cached"}`)
+		is.Equal(req.Header.Get("Content-Type"), "application/vnd.test+json")
+
+		// fail the first attempts, so that requests are retried
+		if attempts.Add(1) <= 10 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = writer.Write([]byte(`{"reply":"Hi"}`))
+	}))
+
+	defer server.Close()
+
+	var opened, closed atomic.Int32
+
+	client := New(WithClock(newFakeClock()), WithResponseErrors(), WithMaxAttempts(20))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, nil,
+		WithRawRequestBodyFunc[*testReq, *testRes](func() (io.ReadCloser, error) {
+			opened.Add(1)
+
+			return &closeCountingReader{
+				Reader: strings.NewReader(`{"message":"cached"}`),
+				closed: &closed,
+			}, nil
+		}, "application/vnd.test+json"),
+	)
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			res, err := Do(context.Background(), client, req)
+			is.NoErr(err)
+			is.Equal(res.Res.Reply, "Hi")
+		}()
+	}
+
+	wg.Wait()
+
+	is.Equal(opened.Load(), attempts.Load())
+	is.Equal(closed.Load(), opened.Load())
+}
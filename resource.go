@@ -0,0 +1,69 @@
+package gojsonclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Resource is a helper to access a REST resource with the usual CRUD operations.
+//
+// Items are addressed by appending their ID to the resource's path. All methods accept request options
+// to further configure the requests made.
+type Resource[Req any, Res any] struct {
+	client *Client
+	path   string
+}
+
+// NewResource creates a new Resource that uses client to access the resource at path.
+// path is appended to the client's base URI as with any other request.
+func NewResource[Req any, Res any](client *Client, path string) *Resource[Req, Res] {
+	return &Resource[Req, Res]{
+		client: client,
+		path:   path,
+	}
+}
+
+// Get retrieves the item with the given ID using an http.MethodGet request without a body.
+func (r *Resource[Req, Res]) Get(ctx context.Context, id string, opts ...RequestOpt[Req, Res]) (*Response[Res], error) {
+	var req Req
+	return Do(ctx, r.client, newBodylessRequest(r.itemURI(id), http.MethodGet, req, opts))
+}
+
+// List retrieves all items matching query using an http.MethodGet request without a body.
+// query may be nil.
+func (r *Resource[Req, Res]) List(ctx context.Context, query url.Values, opts ...RequestOpt[Req, []Res]) (*Response[[]Res], error) {
+	var req Req
+
+	opts = append([]RequestOpt[Req, []Res]{WithQueryParams[Req, []Res](query)}, opts...)
+
+	return Do(ctx, r.client, newBodylessRequest(r.path, http.MethodGet, req, opts))
+}
+
+// Create creates a new item using an http.MethodPost request with body as the request data.
+func (r *Resource[Req, Res]) Create(ctx context.Context, body Req, opts ...RequestOpt[Req, Res]) (*Response[Res], error) {
+	return Do(ctx, r.client, NewRequest(r.path, http.MethodPost, body, opts...))
+}
+
+// Update replaces the item with the given ID using an http.MethodPut request with body as the request data.
+func (r *Resource[Req, Res]) Update(ctx context.Context, id string, body Req, opts ...RequestOpt[Req, Res]) (*Response[Res], error) {
+	return Do(ctx, r.client, NewRequest(r.itemURI(id), http.MethodPut, body, opts...))
+}
+
+// Delete deletes the item with the given ID using an http.MethodDelete request without a body.
+func (r *Resource[Req, Res]) Delete(ctx context.Context, id string, opts ...RequestOpt[Req, Res]) (*Response[Res], error) {
+	var req Req
+	return Do(ctx, r.client, newBodylessRequest(r.itemURI(id), http.MethodDelete, req, opts))
+}
+
+func (r *Resource[Req, Res]) itemURI(id string) string {
+	return r.path + "/" + url.PathEscape(id)
+}
+
+func newBodylessRequest[Req any, Res any](uri string, method string, req Req, opts []RequestOpt[Req, Res]) *Request[Req, Res] {
+	request := NewRequest(uri, method, req, opts...)
+	request.sendBody = sendBodyOmit
+	request.bodyless = true
+
+	return request
+}
@@ -0,0 +1,74 @@
+package gojsonclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	"github.com/matryer/is"
+)
+
+func TestResource(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		switch req.Method + " " + req.URL.Path {
+		case "GET /items/a b":
+			is.Equal(len(body), 0)
+			_ = json.MarshalWrite(writer, &testRes{Reply: "get"})
+
+		case "GET /items":
+			is.Equal(len(body), 0)
+			is.Equal(req.URL.Query().Get("q"), "foo")
+			_ = json.MarshalWrite(writer, []*testRes{{Reply: "list1"}, {Reply: "list2"}})
+
+		case "POST /items":
+			is.Equal(string(body), `{"message":"create"}`)
+			_ = json.MarshalWrite(writer, &testRes{Reply: "created"})
+
+		case "PUT /items/1":
+			is.Equal(string(body), `{"message":"update"}`)
+			_ = json.MarshalWrite(writer, &testRes{Reply: "updated"})
+
+		case "DELETE /items/1":
+			is.Equal(len(body), 0)
+			http.Error(writer, "No Content", http.StatusNoContent)
+
+		default:
+			is.Fail()
+		}
+	}))
+
+	defer server.Close()
+
+	client := New(WithBaseURI(server.URL))
+	items := NewResource[*testReq, *testRes](client, "/items")
+	ctx := context.Background()
+
+	res, err := items.Get(ctx, "a b")
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "get")
+
+	listRes, err := items.List(ctx, url.Values{"q": []string{"foo"}})
+	is.NoErr(err)
+	is.Equal(len(listRes.Res), 2)
+	is.Equal(listRes.Res[1].Reply, "list2")
+
+	res, err = items.Create(ctx, &testReq{Message: "create"})
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "created")
+
+	res, err = items.Update(ctx, "1", &testReq{Message: "update"})
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "updated")
+
+	res, err = items.Delete(ctx, "1", WithIgnoreResponseBody[*testReq, *testRes]())
+	is.NoErr(err)
+	is.Equal(res.StatusCode, http.StatusNoContent)
+}
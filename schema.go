@@ -0,0 +1,116 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-json-experiment/json"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaValidationError is returned when the response body does not match the JSON Schema configured
+// using WithResponseSchema.
+type SchemaValidationError struct {
+	// Violations contains all violations of the schema.
+	Violations []SchemaViolation
+}
+
+// SchemaViolation describes a single violation of a JSON Schema.
+type SchemaViolation struct {
+	// InstanceLocation is the JSON pointer to the offending value in the response body.
+	InstanceLocation string
+
+	// KeywordLocation is the JSON pointer to the violated keyword in the schema.
+	KeywordLocation string
+
+	// Message describes the violation.
+	Message string
+}
+
+var _ error = (*SchemaValidationError)(nil)
+
+// WithResponseSchema configures a Request to validate the response body against the JSON Schema schema
+// before decoding it. If the response body does not match the schema, a *SchemaValidationError is returned.
+// The response body is buffered in memory for validation. WithResponseSchema panics if schema is invalid.
+func WithResponseSchema[Req any, Res any](schema []byte) RequestOpt[Req, Res] {
+	compiler := jsonschema.NewCompiler()
+
+	if err := compiler.AddResource("schema.json", bytes.NewReader(schema)); err != nil {
+		panic("invalid schema: " + err.Error())
+	}
+
+	compiledSchema, err := compiler.Compile("schema.json")
+	if err != nil {
+		panic("invalid schema: " + err.Error())
+	}
+
+	return func(req *Request[Req, Res]) {
+		req.responseSchema = compiledSchema
+	}
+}
+
+// validateSchema validates the response body against schema and returns a copy of httpRes whose body
+// yields the full response body again.
+func validateSchema(httpRes *http.Response, schema *jsonschema.Schema) (*http.Response, error) {
+	body, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+
+	var val any
+	if err = json.Unmarshal(body, &val); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	if err = schema.Validate(val); err != nil {
+		var validationErr *jsonschema.ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, fmt.Errorf("validate response: %w", err)
+		}
+
+		return nil, &SchemaValidationError{
+			Violations: schemaViolations(validationErr, nil),
+		}
+	}
+
+	validatedRes := *httpRes
+	validatedRes.Body = io.NopCloser(bytes.NewReader(body))
+
+	return &validatedRes, nil
+}
+
+func schemaViolations(err *jsonschema.ValidationError, violations []SchemaViolation) []SchemaViolation {
+	if len(err.Causes) == 0 {
+		return append(violations, SchemaViolation{
+			InstanceLocation: err.InstanceLocation,
+			KeywordLocation:  err.KeywordLocation,
+			Message:          err.Message,
+		})
+	}
+
+	for _, cause := range err.Causes {
+		violations = schemaViolations(cause, violations)
+	}
+
+	return violations
+}
+
+// Error implements error.
+func (e *SchemaValidationError) Error() string {
+	if len(e.Violations) == 0 {
+		return "response does not match schema"
+	}
+
+	violation := e.Violations[0]
+	msg := "response does not match schema: " + strconv.Quote(violation.InstanceLocation) + ": " + violation.Message
+
+	if len(e.Violations) > 1 {
+		msg += " (and " + strconv.Itoa(len(e.Violations)-1) + " more)"
+	}
+
+	return msg
+}
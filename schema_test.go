@@ -0,0 +1,63 @@
+package gojsonclient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const testSchema = `{
+	"type": "object",
+	"properties": {
+		"reply": {"type": "string"}
+	},
+	"required": ["reply"]
+}`
+
+func TestResponse_Schema(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithResponseSchema[any, *testRes]([]byte(testSchema)),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "OK",
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{"reply":"Hello, client!"}`))),
+	}
+
+	res, err := response(New(), &httpRes, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "Hello, client!")
+}
+
+func TestResponse_Schema_Violation(t *testing.T) {
+	is := is.New(t)
+
+	req := NewRequest("", http.MethodGet, nil,
+		WithResponseSchema[any, *testRes]([]byte(testSchema)),
+
+		WithUnmarshalResponseFunc[any](func(_ *http.Response, _ **testRes) error {
+			is.Fail()
+			return nil
+		}),
+	)
+
+	httpRes := http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "OK",
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{"message":"Hello, client!"}`))),
+	}
+
+	_, err := response(New(), &httpRes, req)
+
+	var schemaErr *SchemaValidationError
+	is.True(errors.As(err, &schemaErr))
+	is.Equal(len(schemaErr.Violations), 1)
+	is.Equal(schemaErr.Violations[0].KeywordLocation, "/required")
+}
@@ -0,0 +1,70 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrClientClosed is returned when a request is made using a Client that has been shut down using Client.Shutdown.
+var ErrClientClosed = errors.New("client closed")
+
+// Shutdown shuts down the client gracefully. It stops accepting new requests, waits for all in-flight requests
+// to complete, and then closes the idle connections of the client's HTTP transport. Requests made after Shutdown
+// has been called fail with ErrClientClosed.
+//
+// Idle connections are only closed if the transport has been created by the client, that is, if the client has
+// been configured using transport options such as WithDialer, WithMaxIdleConns, or WithHTTP1Hosts. The transports
+// of http.DefaultClient and of HTTP clients configured using WithHTTPClient may be shared with other code, and
+// are left untouched.
+//
+// Requests made using Do and DoDuplex are in flight until they return. Streams opened using DoStream or DoRaw
+// are in flight until they are closed. A Server-Sent Events stream read using DoSSE is in flight while connected;
+// reconnecting to it fails with ErrClientClosed.
+//
+// If ctx expires before all in-flight requests have completed, Shutdown returns the context's error, and idle
+// connections are not closed. Shutdown may be called again to continue waiting.
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.shutdownMutex.Lock()
+	c.closed = true
+	c.shutdownMutex.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		c.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		if c.ownsTransport {
+			c.httpClient.CloseIdleConnections()
+		}
+
+		return nil
+
+	case <-ctx.Done():
+		return fmt.Errorf("wait for in-flight requests: %w", ctx.Err())
+	}
+}
+
+// acquire registers a new in-flight request. It returns ErrClientClosed if the client has been shut down.
+// If acquire returns nil, release must be called when the request has completed.
+func (c *Client) acquire() error {
+	c.shutdownMutex.Lock()
+	defer c.shutdownMutex.Unlock()
+
+	if c.closed {
+		return ErrClientClosed
+	}
+
+	c.inFlight.Add(1)
+
+	return nil
+}
+
+// release unregisters an in-flight request registered using acquire.
+func (c *Client) release() {
+	c.inFlight.Done()
+}
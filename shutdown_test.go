@@ -0,0 +1,160 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestClient_Shutdown(t *testing.T) {
+	is := is.New(t)
+
+	received := make(chan struct{})
+	proceed := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		close(received)
+		<-proceed
+
+		_, _ = writer.Write([]byte(`{"reply":"Hello, client!"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithMaxAttempts(1))
+
+	type result struct {
+		res *Response[*testRes]
+		err error
+	}
+
+	resultCh := make(chan result, 1)
+
+	go func() {
+		req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+		res, err := Do(context.Background(), client, req)
+		resultCh <- result{res, err}
+	}()
+
+	<-received
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := client.Shutdown(ctx)
+	is.True(errors.Is(err, context.Canceled))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err = Do(context.Background(), client, req)
+	is.True(errors.Is(err, ErrClientClosed))
+
+	_, err = DoStream(context.Background(), client, req)
+	is.True(errors.Is(err, ErrClientClosed))
+
+	close(proceed)
+
+	is.NoErr(client.Shutdown(context.Background()))
+
+	inFlight := <-resultCh
+	is.NoErr(inFlight.err)
+	is.Equal(inFlight.res.Res.Reply, "Hello, client!")
+}
+
+func TestClient_Shutdown_Stream(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"reply":"a"}`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	stream, err := DoStream(context.Background(), client, req)
+	is.NoErr(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = client.Shutdown(ctx)
+	is.True(errors.Is(err, context.Canceled))
+
+	is.NoErr(stream.Close())
+	is.NoErr(stream.Close())
+
+	is.NoErr(client.Shutdown(context.Background()))
+}
+
+type closeCountingTransport struct {
+	http.RoundTripper
+
+	closed atomic.Int32
+}
+
+func (t *closeCountingTransport) CloseIdleConnections() {
+	t.closed.Add(1)
+}
+
+func TestClient_Shutdown_SharedTransport(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"reply":"a"}`))
+	}))
+
+	defer server.Close()
+
+	transport := closeCountingTransport{RoundTripper: http.DefaultTransport}
+
+	client := New(WithHTTPClient(&http.Client{Transport: &transport}), WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.NoErr(client.Shutdown(context.Background()))
+	is.Equal(transport.closed.Load(), int32(0))
+}
+
+func TestClient_Shutdown_OwnedTransport(t *testing.T) {
+	is := is.New(t)
+
+	closed := make(chan struct{}, 1)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"reply":"a"}`))
+	}))
+
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+
+	server.Start()
+	defer server.Close()
+
+	client := New(WithMaxIdleConns(10), WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.NoErr(client.Shutdown(context.Background()))
+
+	// blocks until the idle connection has been closed
+	<-closed
+}
@@ -0,0 +1,84 @@
+package gojsonclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestWithBodyAwareMiddleware(t *testing.T) {
+	is := is.New(t)
+
+	var bodies []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		data, _ := io.ReadAll(req.Body)
+		is.Equal(req.Header.Get("X-Body"), string(data))
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithBodyAwareMiddleware(func(req *http.Request, body []byte) error {
+			bodies = append(bodies, string(body))
+			req.Header.Set("X-Body", string(body))
+
+			return nil
+		}),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodPost, &testReq{Message: "Hello, server!"})
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	req = NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil,
+		WithSendBody[*testReq, *testRes](false),
+	)
+
+	_, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	is.Equal(bodies, []string{`{"message":"Hello, server!"}`, ""})
+}
+
+func TestHMACSigner(t *testing.T) {
+	is := is.New(t)
+
+	secret := []byte("secret")
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		data, _ := io.ReadAll(req.Body)
+		bodyHash := sha256.Sum256(data)
+
+		is.Equal(req.Header.Get("X-Content-SHA256"), hex.EncodeToString(bodyHash[:]))
+		is.Equal(req.Header.Get("X-Date"), "20240101T000000Z")
+
+		is.Equal(req.Header.Get("Authorization"), "HMAC-SHA256 Credential=key, SignedHeaders=host;x-content-sha256;x-date, "+
+			"Signature="+hmacSignature(req, secret))
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client := New(WithBodyAwareMiddleware(HMACSigner("key", secret, newFakeClock())))
+
+	req := NewRequest[*testReq, *testRes](server.URL+"/foo?a=1", http.MethodPost, &testReq{Message: "Hello, server!"})
+
+	_, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+
+	req = NewRequest[*testReq, *testRes](server.URL+"/foo", http.MethodPost, &testReq{Message: "Hello, server!"})
+
+	_, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+}
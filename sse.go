@@ -0,0 +1,225 @@
+package gojsonclient
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// defaultSSERetryDelay is the delay before reconnecting to a Server-Sent Events stream if the server
+// has not requested a different delay.
+const defaultSSERetryDelay = 3 * time.Second
+
+// Event is a Server-Sent Event.
+type Event[T any] struct {
+	// ID is the event ID, or the ID of the last event that had one.
+	ID string
+
+	// Name is the event name. It is "message" if the server did not send a name.
+	Name string
+
+	// Data is the decoded event data.
+	Data T
+}
+
+// SSEHandlerFunc handles a Server-Sent Event. If it returns an error, DoSSE stops and returns the error.
+type SSEHandlerFunc[T any] func(ctx context.Context, event *Event[T]) error
+
+// sseState is the state of a Server-Sent Events stream that is carried over between connections.
+type sseState struct {
+	lastEventID string
+	retryDelay  time.Duration
+	processed   int
+}
+
+// DoSSE executes req using client and reads the response body as a stream of Server-Sent Events
+// (text/event-stream). The data of each event is decoded into a Res and passed to handler.
+//
+// If the connection is closed by the server or fails, DoSSE reconnects after the delay requested by the server
+// (3 seconds by default), sending the ID of the last event received in the Last-Event-ID header.
+// Establishing each connection is retried in the same way as Do.
+//
+// DoSSE returns when ctx is canceled, handler returns an error, the event data cannot be decoded,
+// a connection cannot be established, or the server responds with http.StatusNoContent. If the server responds
+// with a status code outside the 2xx range, DoSSE fails with a *ResponseError. If the response's Content-Type
+// is not text/event-stream, DoSSE fails with an *UnexpectedContentTypeError that does not contain the response body. Errors are returned
+// as a *PartialStreamError that contains the number of events that have been handled successfully.
+func DoSSE[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res], handler SSEHandlerFunc[Res]) error {
+	state := sseState{
+		retryDelay: defaultSSERetryDelay,
+	}
+
+	if err := readSSEStreams(ctx, client, req, &state, handler); err != nil {
+		return &PartialStreamError{
+			Processed: state.processed,
+			Err:       err,
+		}
+	}
+
+	return nil
+}
+
+// readSSEStreams connects to the Server-Sent Events stream and reads events from it, reconnecting as necessary.
+func readSSEStreams[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res], state *sseState,
+	handler SSEHandlerFunc[Res],
+) error {
+	for {
+		conn, err := openStream(ctx, client, sseRequest(req, state.lastEventID), 0)
+		if err != nil {
+			return err
+		}
+
+		if conn.httpRes.StatusCode == http.StatusNoContent {
+			return conn.close()
+		}
+
+		if err = checkSSEResponse(client, conn.httpRes); err != nil {
+			_ = conn.close()
+			return err
+		}
+
+		err = readSSE(conn, state, unmarshalOptions(client, req), handler)
+
+		_ = conn.close()
+
+		if err != nil {
+			return err
+		}
+
+		if err = sleep(ctx, client.clock, state.retryDelay); err != nil {
+			return err
+		}
+	}
+}
+
+// checkSSEResponse returns a *ResponseError if httpRes has a status code outside the 2xx range, or an
+// *UnexpectedContentTypeError if httpRes is not a Server-Sent Events stream.
+func checkSSEResponse(client *Client, httpRes *http.Response) error {
+	if !isSuccess(httpRes.StatusCode) {
+		return newResponseError(httpRes, client.maxResponseBodySize)
+	}
+
+	contentType := httpRes.Header.Get("Content-Type")
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || mediaType != "text/event-stream" {
+		return &UnexpectedContentTypeError{
+			StatusCode:  httpRes.StatusCode,
+			Status:      httpRes.Status,
+			ContentType: contentType,
+		}
+	}
+
+	return nil
+}
+
+// sseRequest returns a copy of req that requests a Server-Sent Events stream, resuming after lastEventID
+// if it is not empty.
+func sseRequest[Req any, Res any](req *Request[Req, Res], lastEventID string) *Request[Req, Res] {
+	sseReq := *req
+	sseReq.accept = cmp.Or(req.accept, "text/event-stream")
+
+	if lastEventID != "" {
+		sseReq.header = req.header.Clone()
+		if sseReq.header == nil {
+			sseReq.header = http.Header{}
+		}
+
+		sseReq.header.Set("Last-Event-ID", lastEventID)
+	}
+
+	return &sseReq
+}
+
+// readSSE reads events from conn, decodes their data using opts, and passes them to handler until the end of the
+// stream has been reached. It returns nil if the stream has ended or failed, and the connection should be
+// reestablished.
+func readSSE[T any](conn *streamConn, state *sseState, opts []json.Options, handler SSEHandlerFunc[T]) error {
+	reader := bufio.NewReader(conn.body)
+
+	var (
+		name string
+		data strings.Builder
+	)
+
+	for {
+		line, readErr := reader.ReadString('\n')
+
+		if ctxErr := conn.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if readErr != nil {
+			// incomplete events are discarded
+			return nil
+		}
+
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if data.Len() > 0 {
+				if err := dispatchSSE(conn.ctx, state.lastEventID, cmp.Or(name, "message"), data.String(), opts, handler); err != nil {
+					return err
+				}
+
+				state.processed++
+			}
+
+			name = ""
+
+			data.Reset()
+
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			name = value
+
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				state.lastEventID = value
+			}
+
+		case "retry":
+			if millis, err := strconv.Atoi(value); err == nil && millis >= 0 {
+				state.retryDelay = time.Duration(millis) * time.Millisecond
+			}
+		}
+	}
+}
+
+func dispatchSSE[T any](ctx context.Context, id string, name string, data string, opts []json.Options,
+	handler SSEHandlerFunc[T],
+) error {
+	event := Event[T]{
+		ID:   id,
+		Name: name,
+	}
+
+	if err := json.Unmarshal([]byte(strings.TrimSuffix(data, "\n")), &event.Data, opts...); err != nil {
+		return &DecodeError{
+			Err:     err,
+			RawBody: []byte(data),
+		}
+	}
+
+	return handler(ctx, &event)
+}
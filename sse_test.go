@@ -0,0 +1,171 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDoSSE(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.Header.Get("Accept"), "text/event-stream")
+
+		writer.Header().Set("Content-Type", "text/event-stream")
+		_, _ = writer.Write([]byte(": comment\n" +
+			"data: {\"reply\":\"a\"}\n\n" +
+			"event: update\nid: 1\ndata: {\"reply\":\n" +
+			"data: \"b\"}\n\n"))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	var events []*Event[*testRes]
+
+	errDone := errors.New("done")
+
+	err := DoSSE(context.Background(), client, req, func(_ context.Context, event *Event[*testRes]) error {
+		events = append(events, event)
+
+		if len(events) == 2 {
+			return errDone
+		}
+
+		return nil
+	})
+
+	is.True(errors.Is(err, errDone))
+	is.Equal(len(events), 2)
+
+	var partialErr *PartialStreamError
+	is.True(errors.As(err, &partialErr))
+	is.Equal(partialErr.Processed, 1)
+
+	is.Equal(events[0].Name, "message")
+	is.Equal(events[0].ID, "")
+	is.Equal(events[0].Data.Reply, "a")
+
+	is.Equal(events[1].Name, "update")
+	is.Equal(events[1].ID, "1")
+	is.Equal(events[1].Data.Reply, "b")
+}
+
+func TestDoSSE_Reconnect(t *testing.T) {
+	is := is.New(t)
+
+	connections := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		connections++
+
+		switch connections {
+		case 1:
+			is.Equal(req.Header.Get("Last-Event-ID"), "")
+			writer.Header().Set("Content-Type", "text/event-stream")
+			_, _ = writer.Write([]byte("retry: 10\nid: 42\ndata: {\"reply\":\"a\"}\n\n"))
+
+		case 2:
+			is.Equal(req.Header.Get("Last-Event-ID"), "42")
+			writer.Header().Set("Content-Type", "text/event-stream")
+			_, _ = writer.Write([]byte("data: {\"reply\":\"b\"}\n\n"))
+
+		default:
+			writer.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	var replies []string
+
+	err := DoSSE(context.Background(), client, req, func(_ context.Context, event *Event[*testRes]) error {
+		replies = append(replies, event.Data.Reply)
+		return nil
+	})
+
+	is.NoErr(err)
+	is.Equal(connections, 3)
+	is.Equal(replies, []string{"a", "b"})
+}
+
+func TestDoSSE_DecodeError(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/event-stream")
+		_, _ = writer.Write([]byte("data: {\n\n"))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	err := DoSSE(context.Background(), client, req, func(_ context.Context, _ *Event[*testRes]) error {
+		return nil
+	})
+
+	var decodeErr *DecodeError
+	is.True(errors.As(err, &decodeErr))
+}
+
+func TestDoSSE_ErrorStatus(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/event-stream")
+		writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = writer.Write([]byte("data: {\"reply\":\"a\"}\n\n"))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()), WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	err := DoSSE(context.Background(), client, req, func(_ context.Context, _ *Event[*testRes]) error {
+		return nil
+	})
+
+	var resErr *ResponseError
+	is.True(errors.As(err, &resErr))
+	is.Equal(resErr.StatusCode, http.StatusInternalServerError)
+}
+
+func TestDoSSE_UnexpectedContentType(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		_, _ = writer.Write([]byte("<html></html>"))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()), WithMaxAttempts(1))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	err := DoSSE(context.Background(), client, req, func(_ context.Context, _ *Event[*testRes]) error {
+		return nil
+	})
+
+	var contentTypeErr *UnexpectedContentTypeError
+	is.True(errors.As(err, &contentTypeErr))
+	is.Equal(contentTypeErr.ContentType, "text/html")
+}
@@ -0,0 +1,331 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/blizzy78/gobackoff"
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// Stream is a stream of values decoded incrementally from a response body containing a sequence of
+// JSON values, such as newline-delimited JSON (NDJSON).
+//
+// A Stream must be closed after use.
+type Stream[T any] struct {
+	// StatusCode is the HTTP response status code.
+	StatusCode int
+
+	// Status is the HTTP response status.
+	Status string
+
+	// Header is the HTTP response header.
+	Header http.Header
+
+	conn      *streamConn
+	decoder   *jsontext.Decoder
+	options   []json.Options
+	value     T
+	processed int
+	err       error
+}
+
+// PartialStreamError is returned when reading a stream fails after some values have already been processed
+// successfully. Values that have been processed before the error occurred are not affected.
+type PartialStreamError struct {
+	// Processed is the number of values that have been processed successfully.
+	Processed int
+
+	// Err is the error that interrupted the stream.
+	Err error
+}
+
+var _ error = (*PartialStreamError)(nil)
+
+// streamConn is an open HTTP response whose body is read incrementally.
+type streamConn struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	httpRes *http.Response
+	body    io.Reader
+	release func()
+}
+
+// DoStream executes req using client and returns a Stream that decodes the response body incrementally
+// as a sequence of JSON values. Establishing the connection is retried in the same way as Do,
+// but once the response status has been received, decoding errors are not retried.
+//
+// The Client's request timeout is not applied to reading the response body. To stop reading
+// the response body, cancel ctx or close the Stream.
+func DoStream[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res]) (*Stream[Res], error) {
+	conn, err := openStream(ctx, client, req, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := unmarshalOptions(client, req)
+
+	return &Stream[Res]{
+		StatusCode: conn.httpRes.StatusCode,
+		Status:     conn.httpRes.Status,
+		Header:     conn.httpRes.Header,
+		conn:       conn,
+		decoder:    jsontext.NewDecoder(conn.body, opts...),
+		options:    opts,
+	}, nil
+}
+
+// DoRaw executes req using client and returns the HTTP response without decoding the response body, so that the
+// caller may decode it in any way. The request is made and retried in the same way as Do. The Client's request timeout
+// applies to each attempt, including reading the response body of the final attempt.
+//
+// If the Client has been configured using WithDecompression, the returned response body is already decompressed.
+// The caller must call the returned close function after reading the response body.
+func DoRaw[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res],
+) (*http.Response, func() error, error) {
+	conn, err := openStream(ctx, client, req, client.requestTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	httpRes := *conn.httpRes
+	httpRes.Body = io.NopCloser(conn.body)
+
+	return &httpRes, conn.close, nil
+}
+
+// openStream executes req using client and returns the open response, retrying in the same way as Do.
+// If timeout is greater than 0, it applies to each attempt, including reading the response body.
+func openStream[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res], timeout time.Duration,
+) (*streamConn, error) {
+	if err := client.acquire(); err != nil {
+		return nil, err
+	}
+
+	var conn *streamConn
+
+	start := client.clock.Now()
+	jitter := client.newJitterState()
+	maxAttempts := requestMaxAttempts(client, req)
+
+	ctx = withMarshalOnce(ctx, req)
+
+	err := client.backoff.Do(ctx, func(ctx context.Context) error {
+		var (
+			httpReq *http.Request
+			httpRes *http.Response
+			err     error
+		)
+
+		conn, httpReq, httpRes, err = openStreamAttempt(ctx, client, req, timeout) //nolint:bodyclose // body is closed by streamConn.close
+
+		var tooLargeErr *RequestTooLargeError
+		if errors.Is(err, context.Canceled) || errors.As(err, &tooLargeErr) {
+			return &gobackoff.AbortError{
+				Err: err,
+			}
+		}
+
+		if retryErr := client.retryFunc(ctx, newRetryInfo(ctx, start, client.clock.Now(), httpReq, httpRes, err)); retryErr != nil {
+			if conn != nil {
+				conn.discard()
+				conn = nil
+			}
+
+			return &gobackoff.AbortError{
+				Err: retryErr,
+			}
+		}
+
+		if err != nil && gobackoff.AttemptFromContext(ctx) < maxAttempts {
+			if sleepErr := retrySleep(ctx, client.clock, jitter.next(), err); sleepErr != nil {
+				return &gobackoff.AbortError{
+					Err: sleepErr,
+				}
+			}
+		}
+
+		return err
+	}, maxAttempts)
+
+	if err != nil && client.unwrapFinalError {
+		err = unwrapBackoffError(err)
+	}
+
+	if err != nil {
+		client.release()
+		return nil, err //nolint:wrapcheck // we don't add new info here
+	}
+
+	conn.release = sync.OnceFunc(client.release)
+
+	return conn, nil
+}
+
+func openStreamAttempt[Req any, Res any](ctx context.Context, client *Client, req *Request[Req, Res], timeout time.Duration,
+) (*streamConn, *http.Request, *http.Response, error) {
+	var cancel context.CancelFunc
+
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	httpReq, err := newHTTPRequest(ctx, client, req)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, fmt.Errorf("new HTTP request: %w", err)
+	}
+
+	client.logger.InfoContext(ctx, "execute HTTP stream request",
+		slog.Group("request",
+			slog.String("uri", httpReq.URL.String()),
+			slog.String("method", httpReq.Method),
+		),
+		slog.Int("attempt", gobackoff.AttemptFromContext(ctx)),
+	)
+
+	if req.beforeSend != nil {
+		if err = req.beforeSend(httpReq); err != nil {
+			cancel()
+			return nil, httpReq, nil, fmt.Errorf("before send: %w", err)
+		}
+	}
+
+	httpRes, err := executeHTTPRequest(client, httpReq, req.method, requestURI(client, req))
+	if err != nil {
+		cancel()
+		return nil, httpReq, httpRes, fmt.Errorf("execute HTTP request: %w", err)
+	}
+
+	fail := func(err error) (*streamConn, *http.Request, *http.Response, error) {
+		_ = httpRes.Body.Close()
+
+		cancel()
+
+		return nil, httpReq, httpRes, err
+	}
+
+	assumeContentType(httpRes, client.assumeContentType)
+
+	for _, m := range client.responseMiddlewares {
+		if err = m(httpRes); err != nil {
+			return fail(fmt.Errorf("response middleware: %w", err))
+		}
+	}
+
+	if !isSuccess(httpRes.StatusCode) && client.responseErrors {
+		return fail(newResponseError(httpRes, client.maxResponseBodySize))
+	}
+
+	decodeRes := httpRes
+
+	if requestDecompression(client, req) {
+		if decodeRes, err = decompress(httpRes); err != nil {
+			return fail(fmt.Errorf("decompress response: %w", err))
+		}
+	}
+
+	return &streamConn{
+		ctx:     ctx,
+		cancel:  cancel,
+		httpRes: httpRes,
+		body:    decodeRes.Body,
+	}, httpReq, httpRes, nil
+}
+
+// discard closes the response body and cancels the context of an attempt that is not used.
+func (c *streamConn) discard() {
+	_ = c.httpRes.Body.Close()
+
+	c.cancel()
+}
+
+func (c *streamConn) close() error {
+	defer c.release()
+	defer c.cancel()
+
+	if err := c.httpRes.Body.Close(); err != nil {
+		return fmt.Errorf("close response body: %w", err)
+	}
+
+	return nil
+}
+
+// Next decodes the next value from the stream. It returns false when the end of the stream has been
+// reached or an error has occurred. Err should be consulted to distinguish between the two cases.
+func (s *Stream[T]) Next() bool {
+	if s.err != nil {
+		return false
+	}
+
+	if err := s.conn.ctx.Err(); err != nil {
+		s.fail(err)
+		return false
+	}
+
+	var value T
+
+	if err := json.UnmarshalDecode(s.decoder, &value, s.options...); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false
+		}
+
+		if ctxErr := s.conn.ctx.Err(); ctxErr != nil {
+			s.fail(ctxErr)
+			return false
+		}
+
+		s.fail(&DecodeError{
+			Err: err,
+		})
+
+		return false
+	}
+
+	s.value = value
+	s.processed++
+
+	return true
+}
+
+func (s *Stream[T]) fail(err error) {
+	s.err = &PartialStreamError{
+		Processed: s.processed,
+		Err:       err,
+	}
+}
+
+// Value returns the value decoded by the last call to Next.
+func (s *Stream[T]) Value() T {
+	return s.value
+}
+
+// Err returns the first error that occurred while decoding the stream, if any, as a *PartialStreamError.
+// Reaching the end of the stream is not considered an error.
+func (s *Stream[T]) Err() error {
+	return s.err
+}
+
+// Close closes the stream and the underlying response body.
+func (s *Stream[T]) Close() error {
+	return s.conn.close()
+}
+
+// Error implements error.
+func (e *PartialStreamError) Error() string {
+	return fmt.Sprintf("stream interrupted after %d values: %s", e.Processed, e.Err)
+}
+
+// Unwrap returns e.Err.
+func (e *PartialStreamError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,239 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	"github.com/matryer/is"
+)
+
+func TestDoStream(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = writer.Write([]byte("{\"reply\":\"a\"}\n{\"reply\":\"b\"}\n{\"reply\":\"c\"}\n"))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	stream, err := DoStream(context.Background(), client, req)
+	is.NoErr(err)
+
+	defer stream.Close() //nolint:errcheck // test
+
+	is.Equal(stream.StatusCode, http.StatusOK)
+
+	var values []string
+
+	for stream.Next() {
+		values = append(values, stream.Value().Reply)
+	}
+
+	is.NoErr(stream.Err())
+	is.Equal(values, []string{"a", "b", "c"})
+}
+
+func TestDoStream_DecodeError(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte("{\"reply\":\"a\"}\n{\"reply\":"))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	stream, err := DoStream(context.Background(), client, req)
+	is.NoErr(err)
+
+	defer stream.Close() //nolint:errcheck // test
+
+	is.True(stream.Next())
+	is.Equal(stream.Value().Reply, "a")
+
+	is.True(!stream.Next())
+
+	var decodeErr *DecodeError
+	is.True(errors.As(stream.Err(), &decodeErr))
+}
+
+func TestDoStream_Interrupted(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		// the connection is closed before the declared length has been written
+		writer.Header().Set("Content-Length", "1000")
+		_, _ = writer.Write([]byte("{\"reply\":\"a\"}\n{\"reply\":\"b\"}\n{\"reply\":"))
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	stream, err := DoStream(context.Background(), client, req)
+	is.NoErr(err)
+
+	defer stream.Close() //nolint:errcheck // test
+
+	var values []string
+
+	for stream.Next() {
+		values = append(values, stream.Value().Reply)
+	}
+
+	is.Equal(values, []string{"a", "b"})
+
+	var partialErr *PartialStreamError
+	is.True(errors.As(stream.Err(), &partialErr))
+	is.Equal(partialErr.Processed, 2)
+}
+
+func TestDoStream_Canceled(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		_, _ = writer.Write([]byte("{\"reply\":\"a\"}\n"))
+		writer.(http.Flusher).Flush()
+
+		<-req.Context().Done()
+	}))
+
+	defer server.Close()
+
+	client := New(WithClock(newFakeClock()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := DoStream(ctx, client, req)
+	is.NoErr(err)
+
+	defer stream.Close() //nolint:errcheck // test
+
+	is.True(stream.Next())
+
+	cancel()
+
+	is.True(!stream.Next())
+	is.True(errors.Is(stream.Err(), context.Canceled))
+}
+
+func TestDoStream_ResponseError(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "Service Unavailable", http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithMaxAttempts(1),
+		WithResponseErrors(),
+		WithUnwrapFinalError(),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := DoStream(context.Background(), client, req)
+
+	var resErr *ResponseError
+	is.True(errors.As(err, &resErr))
+	is.Equal(resErr.StatusCode, http.StatusServiceUnavailable)
+}
+
+func TestDoStream_RetryFuncError_ClosesBody(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"reply":"a"}`))
+	}))
+
+	defer server.Close()
+
+	var body *rot13Reader
+
+	errRetry := errors.New("retry")
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithUnwrapFinalError(),
+
+		WithResponseMiddleware(func(httpRes *http.Response) error {
+			body = &rot13Reader{reader: httpRes.Body}
+			httpRes.Body = body
+
+			return nil
+		}),
+
+		WithRetryV2(func(_ context.Context, _ RetryInfo) error {
+			return errRetry
+		}),
+	)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := DoStream(context.Background(), client, req)
+	is.True(errors.Is(err, errRetry))
+	is.True(body.closed)
+}
+
+func TestDoRaw(t *testing.T) {
+	is := is.New(t)
+
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		attempts++
+
+		is.Equal(req.URL.Path, "/shapes/1")
+
+		if attempts == 1 {
+			http.Error(writer, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = writer.Write([]byte(`{"type":"circle","radius":2}`))
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithClock(newFakeClock()),
+		WithBaseURI(server.URL),
+		WithResponseErrors(),
+	)
+
+	req := NewRequest[*testReq, *testRes]("/shapes/1", http.MethodGet, nil)
+
+	httpRes, closeFunc, err := DoRaw(context.Background(), client, req)
+	is.NoErr(err)
+
+	defer closeFunc() //nolint:errcheck // test
+
+	is.Equal(httpRes.StatusCode, http.StatusOK)
+
+	var shape map[string]any
+	is.NoErr(json.UnmarshalRead(httpRes.Body, &shape))
+	is.Equal(shape["type"], "circle")
+	is.Equal(shape["radius"], 2.0)
+
+	is.Equal(attempts, 2)
+}
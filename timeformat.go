@@ -0,0 +1,42 @@
+package gojsonclient
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// WithTimeFormat configures a Request to decode time.Time values in the response body from JSON strings using
+// layout, as described for time.Parse, instead of RFC 3339. JSON values other than strings, such as null,
+// are decoded as usual.
+//
+// WithTimeFormat has no effect if the Request has been configured using WithUnmarshalResponseFunc.
+func WithTimeFormat[Req any, Res any](layout string) RequestOpt[Req, Res] {
+	return func(req *Request[Req, Res]) {
+		req.unmarshalOptions = append(req.unmarshalOptions, json.WithUnmarshalers(timeUnmarshaler(layout)))
+	}
+}
+
+func timeUnmarshaler(layout string) *json.Unmarshalers {
+	return json.UnmarshalFuncV2(func(dec *jsontext.Decoder, val *time.Time, _ json.Options) error {
+		if dec.PeekKind() != '"' {
+			return json.SkipFunc
+		}
+
+		tok, err := dec.ReadToken()
+		if err != nil {
+			return fmt.Errorf("read time: %w", err)
+		}
+
+		parsed, err := time.Parse(layout, tok.String())
+		if err != nil {
+			return fmt.Errorf("parse time: %w", err)
+		}
+
+		*val = parsed
+
+		return nil
+	})
+}
@@ -0,0 +1,45 @@
+package gojsonclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+type testTimeRes struct {
+	Created time.Time  `json:"created"`
+	Updated *time.Time `json:"updated"`
+}
+
+func TestWithTimeFormat(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"created":"16.10.2026 12:34:56","updated":null}`))
+	}))
+
+	defer server.Close()
+
+	client := New(WithMaxAttempts(1))
+
+	req := NewRequest(server.URL, http.MethodGet, nil,
+		WithTimeFormat[*testReq, *testTimeRes]("02.01.2006 15:04:05"),
+	)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.True(res.Res.Created.Equal(time.Date(2026, time.October, 16, 12, 34, 56, 0, time.UTC)))
+	is.Equal(res.Res.Updated, nil)
+
+	req = NewRequest[*testReq, *testTimeRes](server.URL, http.MethodGet, nil)
+
+	_, err = Do(context.Background(), client, req)
+
+	var decodeErr *DecodeError
+	is.True(errors.As(err, &decodeErr))
+}
@@ -0,0 +1,198 @@
+package gojsonclient
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// httpClientOpt is a function that configures the HTTP client of a Client.
+type httpClientOpt func(httpClient *http.Client)
+
+// transportOpt is a function that configures the HTTP transport of a Client.
+type transportOpt func(transport *http.Transport)
+
+// http1HostsTransport is a transport that sends requests to some hosts using HTTP/1.1 only.
+type http1HostsTransport struct {
+	base  *http.Transport
+	http1 *http.Transport
+	hosts []string
+}
+
+var _ http.RoundTripper = (*http1HostsTransport)(nil)
+
+// WithDialer configures a Client to use dialer to establish new network connections, for example to bind to a
+// specific local address or to configure TCP keep-alive.
+//
+// WithDialer modifies the transport of the client's HTTP client. Since http.DefaultClient should not be
+// modified, its transport is cloned first. If the HTTP client has been configured using WithHTTPClient,
+// that client and its transport are cloned instead, so the original is left untouched. The transport must
+// be an *http.Transport (or nil), otherwise New panics.
+func WithDialer(dialer *net.Dialer) ClientOpt {
+	return func(client *Client) {
+		client.transportOpts = append(client.transportOpts, func(transport *http.Transport) {
+			transport.DialContext = dialer.DialContext
+		})
+	}
+}
+
+// WithMaxIdleConns configures a Client to keep at most maxIdleConns idle (keep-alive) connections across all hosts.
+// 0 means no limit. The per-host limit of the transport still applies. This has no effect if keep-alives have been
+// disabled, for example using a dialer configured with a negative KeepAlive period.
+//
+// WithMaxIdleConns modifies the transport of the client's HTTP client in the same way as WithDialer.
+func WithMaxIdleConns(maxIdleConns int) ClientOpt {
+	if maxIdleConns < 0 {
+		panic("maxIdleConns must be >=0")
+	}
+
+	return func(client *Client) {
+		client.transportOpts = append(client.transportOpts, func(transport *http.Transport) {
+			transport.MaxIdleConns = maxIdleConns
+		})
+	}
+}
+
+// WithCookieJar configures a Client to use jar to store cookies received in responses, and to send them
+// in subsequent requests.
+//
+// WithCookieJar modifies the client's HTTP client. Since http.DefaultClient should not be modified, it is
+// cloned first. If the HTTP client has been configured using WithHTTPClient, that client is cloned instead,
+// so the original is left untouched.
+func WithCookieJar(jar http.CookieJar) ClientOpt {
+	return func(client *Client) {
+		client.httpClientOpts = append(client.httpClientOpts, func(httpClient *http.Client) {
+			httpClient.Jar = jar
+		})
+	}
+}
+
+// WithRedirectPolicy configures a Client to use policy to decide whether to follow redirects, as described for
+// http.Client.CheckRedirect. If policy returns http.ErrUseLastResponse, the redirect response is returned to the
+// caller intact, including its headers, and the response body is ignored.
+//
+// WithRedirectPolicy modifies the client's HTTP client in the same way as WithCookieJar.
+func WithRedirectPolicy(policy func(req *http.Request, via []*http.Request) error) ClientOpt {
+	return func(client *Client) {
+		client.httpClientOpts = append(client.httpClientOpts, func(httpClient *http.Client) {
+			httpClient.CheckRedirect = policy
+		})
+	}
+}
+
+// WithNoRedirects configures a Client to never follow redirects. Redirect responses are returned to the caller
+// intact, including their headers, so that the Location header can be inspected.
+//
+// WithNoRedirects modifies the client's HTTP client in the same way as WithCookieJar.
+func WithNoRedirects() ClientOpt {
+	return WithRedirectPolicy(func(_ *http.Request, _ []*http.Request) error {
+		return http.ErrUseLastResponse
+	})
+}
+
+// WithHTTP1Hosts configures a Client to use HTTP/1.1 for requests to hosts, while HTTP/2 may still be used
+// for all other hosts. This is useful for hosts that misbehave when using HTTP/2. A host may be given as
+// "example.com", which matches any port, or as "example.com:8443", which matches only that port.
+// WithHTTP1Hosts may be used multiple times to add more hosts.
+//
+// http.Transport can only disable HTTP/2 for all hosts at once. Therefore, requests to hosts are sent using a
+// separate copy of the client's transport that has HTTP/2 disabled, and that maintains its own connection pool.
+// The copy is made after all other transport options (such as WithDialer) have been applied.
+//
+// WithHTTP1Hosts modifies the transport of the client's HTTP client in the same way as WithDialer.
+func WithHTTP1Hosts(hosts ...string) ClientOpt {
+	return func(client *Client) {
+		client.http1Hosts = append(client.http1Hosts, hosts...)
+	}
+}
+
+// applyHTTPClientOpts replaces the client's HTTP client with a copy whose transport has been configured using
+// the client's transport options, and that has then been configured using the client's HTTP client options.
+// If the transport has been configured, it has been cloned, and so is owned by the client.
+func (c *Client) applyHTTPClientOpts() {
+	if len(c.httpClientOpts) == 0 && len(c.transportOpts) == 0 && len(c.http1Hosts) == 0 {
+		return
+	}
+
+	httpClient := *c.httpClient
+
+	if len(c.transportOpts) != 0 || len(c.http1Hosts) != 0 {
+		transport := configuredTransport(httpClient.Transport, c.transportOpts)
+		httpClient.Transport = transport
+		c.ownsTransport = true
+
+		if len(c.http1Hosts) != 0 {
+			httpClient.Transport = newHTTP1HostsTransport(transport, c.http1Hosts)
+		}
+	}
+
+	for _, opt := range c.httpClientOpts {
+		opt(&httpClient)
+	}
+
+	c.httpClient = &httpClient
+}
+
+func configuredTransport(roundTripper http.RoundTripper, opts []transportOpt) *http.Transport {
+	var transport *http.Transport
+
+	switch t := roundTripper.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert // always *http.Transport
+
+	case *http.Transport:
+		transport = t.Clone()
+
+	default:
+		panic("transport options require the HTTP client's transport to be an *http.Transport")
+	}
+
+	for _, opt := range opts {
+		opt(transport)
+	}
+
+	return transport
+}
+
+func newHTTP1HostsTransport(base *http.Transport, hosts []string) *http1HostsTransport {
+	http1 := base.Clone()
+	http1.ForceAttemptHTTP2 = false
+
+	// a non-nil, empty map disables HTTP/2
+	http1.TLSNextProto = map[string]func(authority string, conn *tls.Conn) http.RoundTripper{}
+
+	if http1.TLSClientConfig != nil {
+		http1.TLSClientConfig.NextProtos = slices.DeleteFunc(slices.Clone(http1.TLSClientConfig.NextProtos), func(proto string) bool {
+			return proto == "h2"
+		})
+	}
+
+	return &http1HostsTransport{
+		base:  base,
+		http1: http1,
+		hosts: hosts,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *http1HostsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.isHTTP1Host(req) {
+		return t.http1.RoundTrip(req) //nolint:wrapcheck // we don't add new info here
+	}
+
+	return t.base.RoundTrip(req) //nolint:wrapcheck // we don't add new info here
+}
+
+// CloseIdleConnections closes the idle connections of both transports.
+func (t *http1HostsTransport) CloseIdleConnections() {
+	t.base.CloseIdleConnections()
+	t.http1.CloseIdleConnections()
+}
+
+func (t *http1HostsTransport) isHTTP1Host(req *http.Request) bool {
+	return slices.ContainsFunc(t.hosts, func(host string) bool {
+		return strings.EqualFold(host, req.URL.Host) || strings.EqualFold(host, req.URL.Hostname())
+	})
+}
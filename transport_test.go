@@ -0,0 +1,203 @@
+package gojsonclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestWithDialer(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	var class ErrorClass
+
+	client := New(
+		WithMaxAttempts(1),
+		WithDialer(&net.Dialer{Timeout: time.Nanosecond}),
+
+		WithOnAttempt(func(_ context.Context, info *AttemptInfo) {
+			class = info.Class
+		}),
+	)
+
+	is.True(client.httpClient != http.DefaultClient)
+	is.Equal(http.DefaultClient.Transport, nil)
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	_, err := Do(context.Background(), client, req)
+	is.True(err != nil)
+	is.Equal(class, ErrorClassTimeout)
+}
+
+func TestWithMaxIdleConns(t *testing.T) {
+	is := is.New(t)
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: 5,
+		},
+	}
+
+	client := New(
+		WithMaxIdleConns(50),
+		WithHTTPClient(httpClient),
+	)
+
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	is.True(ok)
+	is.Equal(transport.MaxIdleConns, 50)
+	is.Equal(transport.MaxIdleConnsPerHost, 5)
+
+	is.Equal(httpClient.Transport.(*http.Transport).MaxIdleConns, 0) //nolint:forcetypeassert // always *http.Transport
+}
+
+func TestWithCookieJar(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/login" {
+			http.SetCookie(writer, &http.Cookie{Name: "session", Value: "123"})
+			http.Error(writer, "No Content", http.StatusNoContent)
+
+			return
+		}
+
+		session, err := req.Cookie("session")
+		is.NoErr(err)
+		is.Equal(session.Value, "123")
+
+		theme, err := req.Cookie("theme")
+		is.NoErr(err)
+		is.Equal(theme.Value, "dark")
+
+		http.Error(writer, "No Content", http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	is.NoErr(err)
+
+	client := New(
+		WithBaseURI(server.URL),
+		WithCookieJar(jar),
+	)
+
+	is.Equal(http.DefaultClient.Jar, nil)
+
+	_, err = Do(context.Background(), client, NewRequest[*testReq, *testRes]("/login", http.MethodPost, nil))
+	is.NoErr(err)
+
+	req := NewRequest("/profile", http.MethodGet, (*testReq)(nil),
+		WithCookie[*testReq, *testRes](&http.Cookie{Name: "theme", Value: "dark"}),
+	)
+
+	_, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+}
+
+func TestWithNoRedirects(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		is.Equal(req.URL.Path, "/short")
+		http.Redirect(writer, req, "/long", http.StatusFound)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithBaseURI(server.URL),
+		WithNoRedirects(),
+		WithResponseErrors(),
+	)
+
+	is.Equal(http.DefaultClient.CheckRedirect, nil)
+
+	res, err := Do(context.Background(), client, NewRequest[*testReq, *testRes]("/short", http.MethodGet, nil))
+	is.NoErr(err)
+	is.Equal(res.StatusCode, http.StatusFound)
+	is.Equal(res.Header.Get("Location"), "/long")
+	is.Equal(res.Res, nil)
+}
+
+func TestWithRedirectPolicy(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		hops, _ := strconv.Atoi(strings.TrimPrefix(req.URL.Path, "/"))
+		http.Redirect(writer, req, "/"+strconv.Itoa(hops+1), http.StatusTemporaryRedirect)
+	}))
+
+	defer server.Close()
+
+	client := New(
+		WithBaseURI(server.URL),
+		WithRedirectPolicy(func(_ *http.Request, via []*http.Request) error {
+			if len(via) >= 2 {
+				return http.ErrUseLastResponse
+			}
+
+			return nil
+		}),
+	)
+
+	res, err := Do(context.Background(), client, NewRequest[*testReq, *testRes]("/0", http.MethodGet, nil))
+	is.NoErr(err)
+	is.Equal(res.StatusCode, http.StatusTemporaryRedirect)
+	is.Equal(res.Header.Get("Location"), "/2")
+}
+
+func TestWithHTTP1Hosts(t *testing.T) {
+	is := is.New(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+		_, _ = writer.Write([]byte(`{"reply":"` + strconv.Itoa(req.ProtoMajor) + `"}`))
+	}))
+
+	server.EnableHTTP2 = true
+	server.StartTLS()
+
+	defer server.Close()
+
+	client := New(WithHTTPClient(server.Client()))
+
+	req := NewRequest[*testReq, *testRes](server.URL, http.MethodGet, nil)
+
+	res, err := Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "2")
+
+	client = New(
+		WithHTTPClient(server.Client()),
+		WithHTTP1Hosts("127.0.0.1"),
+	)
+
+	res, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "1")
+
+	client = New(
+		WithHTTPClient(server.Client()),
+		WithHTTP1Hosts("other.invalid"),
+	)
+
+	res, err = Do(context.Background(), client, req)
+	is.NoErr(err)
+	is.Equal(res.Res.Reply, "2")
+}